@@ -0,0 +1,375 @@
+package codeforces
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache stores raw response bodies for a limited time. Implementations must be
+// safe for concurrent use.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, body []byte, ttl time.Duration)
+}
+
+// CachePolicy sets for how long the responses of each api method are cached.
+type CachePolicy struct {
+	// methods missing from the map aren't cached
+	TTLs map[string]time.Duration
+	// used instead of the contest.standings ttl once a contest is FINISHED,
+	// since its standings won't change anymore. Ignored when 0.
+	FinishedStandingsTTL time.Duration
+	// responses larger than this many bytes aren't cached, so that scanning a
+	// whole contest doesn't keep all of it in memory. 0 means no limit.
+	MaxEntrySize int64
+}
+
+// the MaxEntrySize of DefaultCachePolicy
+const DefaultMaxCacheEntrySize = 8 << 20
+
+// caches slowly changing data for a long time, and activity feeds briefly.
+// user.friends is left out since it depends on the api key.
+func DefaultCachePolicy() CachePolicy {
+	return CachePolicy{
+		TTLs: map[string]time.Duration{
+			"blogEntry.comments":      5 * time.Minute,
+			"blogEntry.view":          time.Hour,
+			"contest.hacks":           10 * time.Minute,
+			"contest.list":            10 * time.Minute,
+			"contest.ratingChanges":   time.Hour,
+			"contest.standings":       time.Minute,
+			"contest.status":          time.Minute,
+			"problemset.problems":     time.Hour,
+			"problemset.recentStatus": 10 * time.Second,
+			"recentActions":           10 * time.Second,
+			"user.blogEntries":        10 * time.Minute,
+			"user.info":               5 * time.Minute,
+			"user.rating":             10 * time.Minute,
+			"user.status":             time.Minute,
+		},
+		FinishedStandingsTTL: 24 * time.Hour,
+		MaxEntrySize:         DefaultMaxCacheEntrySize,
+	}
+}
+
+func (p *CachePolicy) ttl(method string, body []byte) time.Duration {
+	if method == "contest.standings" && p.FinishedStandingsTTL > 0 {
+		standings := ResultWrapper[struct {
+			Contest struct {
+				Phase string `json:"phase"`
+			} `json:"contest"`
+		}]{}
+		if json.Unmarshal(body, &standings) == nil && standings.Result.Contest.Phase == "FINISHED" {
+			return p.FinishedStandingsTTL
+		}
+	}
+	return p.TTLs[method]
+}
+
+// CacheStats counts how many calls were answered from the cache.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// CachingTransport answers calls from Cache when possible, and stores the
+// successful responses of Next according to Policy. Entries are keyed by
+// method and parameters, authentication parameters excluded, so it can sit
+// either above or below a SigningTransport. Calls that require authentication
+// are never cached.
+type CachingTransport struct {
+	Next   Transport
+	Cache  Cache
+	Policy CachePolicy
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// Calls are keyed by their lang parameter. When next is the transport returned
+// by NewTransport, the language set with WithLanguage counts as well, so that a
+// cache can be shared by clients using different languages. Any other layer in
+// between hides that default, and the calls made without a language are then
+// cached together whatever language they're answered in.
+func NewCachingTransport(next Transport, cache Cache) *CachingTransport {
+	return &CachingTransport{Next: next, Cache: cache, Policy: DefaultCachePolicy()}
+}
+
+func (t *CachingTransport) Get(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+	// calls needing authentication, such as manager standings, depend on the
+	// api key, which isn't part of the key
+	_, cacheable := t.Policy.TTLs[method]
+	if !cacheable || requiresAuth(method, params) {
+		return t.Next.Get(ctx, method, params)
+	}
+	// the default language of Next is part of the response, so of the key too
+	if d, ok := t.Next.(interface{ defaultLanguage() string }); ok {
+		if _, ok := params["lang"]; !ok {
+			params = withLanguage(params, d.defaultLanguage())
+		}
+	}
+	key := cacheKey(method, params)
+	if body, ok := t.Cache.Get(key); ok {
+		t.hits.Add(1)
+		return syntheticResponse(method, body), nil
+	}
+	t.misses.Add(1)
+	resp, err := t.Next.Get(ctx, method, params)
+	if err != nil {
+		return nil, err
+	}
+	// the body is still streamed to the caller, and only stored once it has
+	// been read completely and decoded successfully
+	resp.Body = &teeBody{rc: resp.Body, limit: t.Policy.MaxEntrySize, store: func(body []byte) {
+		if ttl := t.Policy.ttl(method, body); ttl > 0 {
+			t.Cache.Set(key, body, ttl)
+		}
+	}}
+	return resp, nil
+}
+
+// passes a body through while keeping a copy of it, which is handed to store
+// when the body is closed after being read to the end and decoded without
+// errors. Bodies read without being fully decoded, e.g. by callers of the
+// transport itself, are stored when they're valid json and their envelope
+// reports success. The copy is dropped
+// as soon as it grows past limit bytes, when limit is positive.
+type teeBody struct {
+	rc       io.ReadCloser
+	buf      bytes.Buffer
+	limit    int64
+	overflow bool
+	eof      bool
+	decoded  bool
+	// error decoding the body failed with, if any
+	decodeErr error
+	closed    bool
+	store     func(body []byte)
+}
+
+func (b *teeBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	if !b.overflow {
+		if b.limit > 0 && int64(b.buf.Len()+n) > b.limit {
+			b.overflow = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF {
+		b.eof = true
+	}
+	return n, err
+}
+
+// lets hooks below the cache learn how decoding went, and keeps the body out
+// of the cache when it failed
+func (b *teeBody) reportDecode(err error) {
+	b.decoded, b.decodeErr = true, err
+	if r, ok := b.rc.(decodeReporter); ok {
+		r.reportDecode(err)
+	}
+}
+
+func (b *teeBody) Close() error {
+	err := b.rc.Close()
+	if b.closed || !b.eof || b.overflow {
+		return err
+	}
+	b.closed = true
+	body := b.buf.Bytes()
+	if b.decoded {
+		if b.decodeErr == nil {
+			b.store(body)
+		}
+	} else if m := envelopeStatus.FindSubmatch(bytes.TrimSpace(body)); m != nil && string(m[1]) == "OK" && json.Valid(body) {
+		b.store(body)
+	}
+	return err
+}
+
+func (t *CachingTransport) Stats() CacheStats {
+	return CacheStats{Hits: t.hits.Load(), Misses: t.misses.Load()}
+}
+
+// method followed by the sorted parameters, without the ones that change with
+// every request
+func cacheKey(method string, params map[string]string) string {
+	public := publicParams(params)
+	keys := make([]string, 0, len(public))
+	for k := range public {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(method)
+	for i, k := range keys {
+		if i == 0 {
+			b.WriteByte('?')
+		} else {
+			b.WriteByte('&')
+		}
+		b.WriteString(k + "=" + public[k])
+	}
+	return b.String()
+}
+
+// MemoryCache keeps up to a fixed number of entries in memory, evicting the
+// least recently used one when full.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	// most recently used entries at the front
+	order *list.List
+}
+
+type memoryEntry struct {
+	key     string
+	body    []byte
+	expires time.Time
+}
+
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*memoryEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.body, true
+}
+
+func (c *MemoryCache) Set(key string, body []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &memoryEntry{key: key, body: body, expires: time.Now().Add(ttl)}
+	if el, ok := c.entries[key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// DiskCache stores every entry in its own file inside a directory, so that it
+// survives restarts. Expired files are removed when read.
+type DiskCache struct {
+	dir string
+}
+
+// the directory is created if it doesn't exist
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// files hold the expiration time as unix nanoseconds, followed by the body
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil || len(data) < 8 {
+		return nil, false
+	}
+	expires := time.Unix(0, int64(binary.BigEndian.Uint64(data[:8])))
+	if time.Now().After(expires) {
+		_ = os.Remove(c.path(key))
+		return nil, false
+	}
+	return data[8:], true
+}
+
+func (c *DiskCache) Set(key string, body []byte, ttl time.Duration) {
+	f, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	var header [8]byte
+	binary.BigEndian.PutUint64(header[:], uint64(time.Now().Add(ttl).UnixNano()))
+	_, err = f.Write(append(header[:], body...))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	// renaming makes sure readers never see a partially written entry
+	if err == nil {
+		err = os.Rename(f.Name(), c.path(key))
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+}
+
+// removes every entry, leaving alone the files of the directory that don't
+// belong to the cache
+func (c *DiskCache) Clear() error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !isDiskCacheFile(e.Name()) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, e.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// entries are named after the sha256 of their key, and written to temporary
+// files first
+func isDiskCacheFile(name string) bool {
+	if strings.HasPrefix(name, ".tmp-") {
+		return true
+	}
+	return len(name) == 2*sha256.Size && strings.Trim(name, "0123456789abcdef") == ""
+}
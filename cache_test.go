@@ -0,0 +1,239 @@
+package codeforces
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// records how many calls reach the api, answering all of them with body
+func countingTransport(calls *int, body string) Transport {
+	return TransportFunc(func(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+		*calls++
+		return jsonResponse(body), nil
+	})
+}
+
+func TestCachingTransport(t *testing.T) {
+	calls := 0
+	next := countingTransport(&calls, `{"status":"OK","result":[{"handle":"tourist"}]}`)
+	ct := NewCachingTransport(next, NewMemoryCache(10))
+	c := NewCustomClient(ct)
+	for i := 0; i < 3; i++ {
+		resp, err := c.User.Info([]string{"tourist"})
+		assert.Nil(t, err)
+		assert.Equal(t, "tourist", (*resp)[0].Handle)
+	}
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 1}, ct.Stats())
+
+	_, err := c.User.Info([]string{"benq"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestCachingTransportIgnoresSignature(t *testing.T) {
+	calls := 0
+	ct := NewCachingTransport(countingTransport(&calls, `{"status":"OK","result":[]}`), NewMemoryCache(10))
+	for _, sig := range []string{"123456aaaa", "654321bbbb"} {
+		params := map[string]string{"gym": "false", "apiKey": "key", "time": sig[:6], "apiSig": sig}
+		resp, err := ct.Get(context.Background(), "contest.list", params)
+		assert.Nil(t, err)
+		_, err = io.ReadAll(resp.Body)
+		assert.Nil(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestCachingTransportSkips(t *testing.T) {
+	calls := 0
+	ct := NewCachingTransport(countingTransport(&calls, `{"status":"OK","result":["tourist"]}`), NewMemoryCache(10))
+	c := NewCustomClient(ct)
+	_, err := c.User.Friends(false)
+	assert.Nil(t, err)
+	_, err = c.User.Friends(false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+
+	failed := 0
+	ct = NewCachingTransport(countingTransport(&failed, `{"status":"FAILED","comment":"Call limit exceeded"}`), NewMemoryCache(10))
+	c = NewCustomClient(ct)
+	_, err = c.Contest.List(false)
+	assert.NotNil(t, err)
+	_, err = c.Contest.List(false)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, failed)
+}
+
+func TestCachingTransportMaxEntrySize(t *testing.T) {
+	calls := 0
+	body := `{"status":"OK","result":[{"id":1},{"id":2},{"id":3}]}`
+	ct := NewCachingTransport(countingTransport(&calls, body), NewMemoryCache(10))
+	ct.Policy.MaxEntrySize = int64(len(body) - 1)
+	cs := contestService{ct}
+	for i := 0; i < 2; i++ {
+		var ids []int
+		err := cs.StatusEach(566, 1, 0, func(s ContestStatus) error {
+			ids = append(ids, s.ID)
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []int{1, 2, 3}, ids)
+	}
+	assert.Equal(t, 2, calls)
+
+	ct.Policy.MaxEntrySize = int64(len(body))
+	for i := 0; i < 2; i++ {
+		resp, err := cs.Status(566, 1, 3)
+		assert.Nil(t, err)
+		assert.Len(t, *resp, 3)
+	}
+	assert.Equal(t, 3, calls)
+}
+
+func TestCachingTransportPartialRead(t *testing.T) {
+	calls := 0
+	ct := NewCachingTransport(countingTransport(&calls, `{"status":"OK","result":[{"id":1},{"id":2}]}`), NewMemoryCache(10))
+	cs := contestService{ct}
+	stop := errors.New("stop")
+	for i := 0; i < 2; i++ {
+		err := cs.StatusEach(566, 1, 0, func(s ContestStatus) error { return stop })
+		assert.Equal(t, stop, err)
+	}
+	// closeBody drains what's left, so the response is complete and cached
+	assert.Equal(t, 1, calls)
+}
+
+func TestCachePolicyFinishedStandings(t *testing.T) {
+	p := DefaultCachePolicy()
+	finished := []byte(`{"status":"OK","result":{"contest":{"id":566,"phase":"FINISHED"},"rows":[]}}`)
+	running := []byte(`{"status":"OK","result":{"contest":{"id":1800,"phase":"CODING"},"rows":[]}}`)
+	assert.Equal(t, 24*time.Hour, p.ttl("contest.standings", finished))
+	assert.Equal(t, time.Minute, p.ttl("contest.standings", running))
+	assert.Equal(t, 10*time.Second, p.ttl("recentActions", nil))
+}
+
+func TestCacheKey(t *testing.T) {
+	a := cacheKey("user.info", map[string]string{"lang": "en", "handles": "tourist", "apiSig": "x", "time": "1"})
+	b := cacheKey("user.info", map[string]string{"handles": "tourist", "lang": "en"})
+	assert.Equal(t, "user.info?handles=tourist&lang=en", a)
+	assert.Equal(t, a, b)
+	assert.Equal(t, "contest.list", cacheKey("contest.list", nil))
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache(2)
+	c.Set("a", []byte("1"), time.Hour)
+	c.Set("b", []byte("2"), time.Hour)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+	// b is now the least recently used entry
+	c.Set("c", []byte("3"), time.Hour)
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	body, ok := c.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, "3", string(body))
+	assert.Equal(t, 2, c.Len())
+
+	c.Set("expired", []byte("4"), -time.Second)
+	_, ok = c.Get("expired")
+	assert.False(t, ok)
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir)
+	assert.Nil(t, err)
+	c.Set("user.info?handles=tourist", []byte(`{"status":"OK"}`), time.Hour)
+	body, ok := c.Get("user.info?handles=tourist")
+	assert.True(t, ok)
+	assert.Equal(t, `{"status":"OK"}`, string(body))
+	_, ok = c.Get("user.info?handles=benq")
+	assert.False(t, ok)
+
+	c.Set("expired", []byte("x"), -time.Second)
+	_, ok = c.Get("expired")
+	assert.False(t, ok)
+
+	// files that aren't entries survive clearing the cache
+	mine := filepath.Join(dir, "notes.txt")
+	assert.Nil(t, os.WriteFile(mine, []byte("mine"), 0o644))
+	assert.Nil(t, c.Clear())
+	_, ok = c.Get("user.info?handles=tourist")
+	assert.False(t, ok)
+	data, err := os.ReadFile(mine)
+	assert.Nil(t, err)
+	assert.Equal(t, "mine", string(data))
+}
+
+func TestCachingTransportDefaultLanguage(t *testing.T) {
+	langs := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		langs = append(langs, r.URL.Query().Get("lang"))
+		_, _ = w.Write([]byte(`{"status":"OK","result":[{"id":1,"name":"` + r.URL.Query().Get("lang") + `"}]}`))
+	}))
+	defer ts.Close()
+	cache := NewMemoryCache(10)
+	client := func(lang string) *Client {
+		return NewCustomClient(NewCachingTransport(NewTransport("", "", WithBaseURL(ts.URL), WithLanguage(lang), WithRateLimiter(nil)), cache))
+	}
+	ru, en := client("ru"), client("en")
+	for i := 0; i < 2; i++ {
+		resp, err := ru.Contest.List(false)
+		assert.Nil(t, err)
+		assert.Equal(t, "ru", (*resp)[0].Name)
+		resp, err = en.Contest.List(false)
+		assert.Nil(t, err)
+		assert.Equal(t, "en", (*resp)[0].Name)
+	}
+	assert.Equal(t, []string{"ru", "en"}, langs)
+}
+
+func TestCachingTransportReportsDecodeErrors(t *testing.T) {
+	var infos []CallInfo
+	hook := HookMiddleware(func(ctx context.Context, info CallInfo) {
+		infos = append(infos, info)
+	})
+	calls := 0
+	tr := NewTransport("", "", WithTransport(countingTransport(&calls, `{"status":"OK","result":{"id":1}}`)),
+		WithRateLimiter(nil), WithRetryPolicy(nil), WithMiddleware(hook))
+	c := NewCustomClient(NewCachingTransport(tr, NewMemoryCache(10)))
+	_, err := c.Contest.List(false)
+	var malformed *MalformedResponseError
+	assert.True(t, errors.As(err, &malformed))
+	assert.Len(t, infos, 1)
+	assert.Equal(t, err, infos[0].Err)
+}
+
+func TestCachingTransportSkipsUndecodable(t *testing.T) {
+	calls := 0
+	ct := NewCachingTransport(countingTransport(&calls, `{"status":"OK","result":{"id":1}}`), NewMemoryCache(10))
+	cs := contestService{ct}
+	for i := 0; i < 2; i++ {
+		_, err := cs.List(false)
+		var malformed *MalformedResponseError
+		assert.True(t, errors.As(err, &malformed))
+	}
+	assert.Equal(t, 2, calls)
+
+	// bodies stopped early are checked as a whole instead
+	calls = 0
+	ct = NewCachingTransport(countingTransport(&calls, `{"status":"OK","result":[{"id":1},{"id":2}`), NewMemoryCache(10))
+	cs = contestService{ct}
+	stop := errors.New("stop")
+	for i := 0; i < 2; i++ {
+		err := cs.StatusEach(566, 1, 0, func(s ContestStatus) error { return stop })
+		assert.Equal(t, stop, err)
+	}
+	assert.Equal(t, 2, calls)
+}
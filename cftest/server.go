@@ -0,0 +1,667 @@
+// Package cftest provides a fake codeforces api server, so that code using the
+// codeforces package can be tested without network access.
+//
+// The server is seeded with typed values (or raw fixture files), and answers
+// calls the way the real api does: from, count and handles are honored,
+// unknown handles and contests result in FAILED responses, and signatures are
+// verified against the registered credentials.
+package cftest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/michelececcacci/codeforces"
+)
+
+// Server is a fake codeforces api. It's safe for concurrent use, and can be
+// seeded while serving.
+type Server struct {
+	srv *httptest.Server
+
+	mu            sync.Mutex
+	users         map[string]codeforces.User
+	ratings       map[string][]codeforces.RatingChange
+	contests      []codeforces.Contest
+	standings     map[int]codeforces.ContestStandings
+	submissions   map[int][]codeforces.ContestStatus
+	hacks         map[int]codeforces.ContestHack
+	ratingChanges map[int][]codeforces.RatingChange
+	problemset    codeforces.Problemset
+	blogEntries   map[int]codeforces.BlogEntry
+	comments      map[int][]codeforces.Comment
+	recentActions []codeforces.RecentAction
+	// friends of the owner of each api key
+	friends map[string][]string
+	// raw bodies served instead of the typed data, by method
+	fixtures map[string][]byte
+	// secrets by api key
+	credentials map[string]string
+	// comments of the FAILED responses queued for each method, "" for any
+	failures      map[string][]string
+	callLimitHits int
+	calls         map[string]int
+}
+
+// starts a new empty server, which must be closed after use
+func NewServer() *Server {
+	s := &Server{
+		users:         map[string]codeforces.User{},
+		ratings:       map[string][]codeforces.RatingChange{},
+		standings:     map[int]codeforces.ContestStandings{},
+		submissions:   map[int][]codeforces.ContestStatus{},
+		hacks:         map[int]codeforces.ContestHack{},
+		ratingChanges: map[int][]codeforces.RatingChange{},
+		blogEntries:   map[int]codeforces.BlogEntry{},
+		comments:      map[int][]codeforces.Comment{},
+		friends:       map[string][]string{},
+		fixtures:      map[string][]byte{},
+		credentials:   map[string]string{},
+		failures:      map[string][]string{},
+		calls:         map[string]int{},
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// base url of the api, to be used with codeforces.WithBaseURL
+func (s *Server) URL() string {
+	return s.srv.URL + "/api/"
+}
+
+// returns a client talking to the server, without rate limiting or retries.
+// Leave apiKey and apiSecret empty for an anonymous client.
+func (s *Server) Client(apiKey, apiSecret string, opts ...codeforces.ClientOption) *codeforces.Client {
+	defaults := []codeforces.ClientOption{
+		codeforces.WithBaseURL(s.URL()),
+		codeforces.WithHTTPClient(s.srv.Client()),
+		codeforces.WithRateLimiter(nil),
+		codeforces.WithRetryPolicy(nil),
+	}
+	return codeforces.NewClient(apiKey, apiSecret, append(defaults, opts...)...)
+}
+
+// number of calls received for method, including failed ones
+func (s *Server) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+// registers an api key, calls signed with it are accepted
+func (s *Server) AddCredentials(apiKey, apiSecret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.credentials[apiKey] = apiSecret
+}
+
+// sets the friends returned by user.friends to the owner of apiKey
+func (s *Server) SetFriends(apiKey string, handles ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.friends[apiKey] = handles
+}
+
+func (s *Server) AddUsers(users ...codeforces.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range users {
+		s.users[strings.ToLower(u.Handle)] = u
+	}
+}
+
+func (s *Server) SetRating(handle string, changes ...codeforces.RatingChange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ratings[strings.ToLower(handle)] = changes
+}
+
+func (s *Server) AddContests(contests ...codeforces.Contest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.contests = append(s.contests, contests...)
+}
+
+// sets the standings of a contest. The contest is also added to contest.list
+// if it isn't there yet.
+func (s *Server) SetStandings(contestId int, standings codeforces.ContestStandings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	standings.Contest.ID = contestId
+	s.standings[contestId] = standings
+	for _, c := range s.contests {
+		if c.ID == contestId {
+			return
+		}
+	}
+	s.contests = append(s.contests, standings.Contest)
+}
+
+// adds submissions to a contest. They're also served by user.status, ordered
+// by decreasing id.
+func (s *Server) AddSubmissions(contestId int, submissions ...codeforces.ContestStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.submissions[contestId] = append(s.submissions[contestId], submissions...)
+}
+
+func (s *Server) SetHacks(contestId int, hacks codeforces.ContestHack) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hacks[contestId] = hacks
+}
+
+func (s *Server) SetRatingChanges(contestId int, changes ...codeforces.RatingChange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ratingChanges[contestId] = changes
+}
+
+func (s *Server) SetProblemset(ps codeforces.Problemset) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.problemset = ps
+}
+
+func (s *Server) AddBlogEntries(entries ...codeforces.BlogEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range entries {
+		s.blogEntries[e.ID] = e
+	}
+}
+
+func (s *Server) SetComments(blogEntryId int, comments ...codeforces.Comment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.comments[blogEntryId] = comments
+}
+
+func (s *Server) SetRecentActions(actions ...codeforces.RecentAction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recentActions = actions
+}
+
+// serves the content of the file at path for every call to method, whatever
+// its parameters, instead of the typed data
+func (s *Server) LoadFixture(method, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fixtures[method] = b
+	return nil
+}
+
+// makes the next call to method fail with a FAILED response carrying comment.
+// An empty method matches any call. Failures queue up.
+func (s *Server) FailNext(method, comment string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[method] = append(s.failures[method], comment)
+}
+
+// makes the next n calls fail with 503 "Call limit exceeded"
+func (s *Server) ExceedCallLimit(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callLimitHits += n
+}
+
+// outcome of a call: a result to encode, or a failure
+type response struct {
+	status  int
+	comment string
+	result  any
+	raw     []byte
+}
+
+func ok(result any) response {
+	return response{status: http.StatusOK, result: result}
+}
+
+func failed(comment string) response {
+	return response{status: http.StatusBadRequest, comment: comment}
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	method := strings.TrimPrefix(r.URL.Path, "/api/")
+	q := r.URL.Query()
+	s.mu.Lock()
+	s.calls[method]++
+	resp := s.respond(method, q)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(resp.status)
+	if resp.raw != nil {
+		_, _ = w.Write(resp.raw)
+		return
+	}
+	if resp.status != http.StatusOK {
+		_ = json.NewEncoder(w).Encode(codeforces.FailedRequest{Status: "FAILED", Comment: resp.comment})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(codeforces.ResultWrapper[any]{Status: "OK", Result: resp.result})
+}
+
+// must be called with the lock held
+func (s *Server) respond(method string, q url.Values) response {
+	if s.callLimitHits > 0 {
+		s.callLimitHits--
+		return response{status: http.StatusServiceUnavailable, comment: "Call limit exceeded"}
+	}
+	for _, m := range []string{method, ""} {
+		if queued := s.failures[m]; len(queued) > 0 {
+			s.failures[m] = queued[1:]
+			return failed(queued[0])
+		}
+	}
+	authenticated, rejection := s.authenticate(method, q)
+	if rejection != nil {
+		return *rejection
+	}
+	if (method == "user.friends" || q.Get("asManager") == "true") && !authenticated {
+		return failed("Unauthorized")
+	}
+	if raw, ok := s.fixtures[method]; ok {
+		return response{status: http.StatusOK, raw: raw}
+	}
+	handler, found := handlers[method]
+	if !found {
+		return response{status: http.StatusNotFound, comment: "Method " + method + " is not supported"}
+	}
+	return handler(s, q)
+}
+
+// checks the signature of calls carrying an api key. A non nil response means
+// the call must be rejected.
+func (s *Server) authenticate(method string, q url.Values) (bool, *response) {
+	key := q.Get("apiKey")
+	if key == "" {
+		return false, nil
+	}
+	secret, known := s.credentials[key]
+	if !known {
+		resp := failed("apiKey: Incorrect API key")
+		return false, &resp
+	}
+	if codeforces.Verify(method, q, secret) != nil {
+		resp := failed("apiSig: Incorrect signature")
+		return false, &resp
+	}
+	return true, nil
+}
+
+var handlers = map[string]func(s *Server, q url.Values) response{
+	"blogEntry.comments":      (*Server).blogEntryComments,
+	"blogEntry.view":          (*Server).blogEntryView,
+	"contest.hacks":           (*Server).contestHacks,
+	"contest.list":            (*Server).contestList,
+	"contest.ratingChanges":   (*Server).contestRatingChanges,
+	"contest.standings":       (*Server).contestStandings,
+	"contest.status":          (*Server).contestStatus,
+	"problemset.problems":     (*Server).problemsetProblems,
+	"problemset.recentStatus": (*Server).problemsetRecentStatus,
+	"recentActions":           (*Server).recentActionsList,
+	"user.blogEntries":        (*Server).userBlogEntries,
+	"user.friends":            (*Server).userFriends,
+	"user.info":               (*Server).userInfo,
+	"user.rating":             (*Server).userRating,
+	"user.ratedList":          (*Server).userRatedList,
+	"user.status":             (*Server).userStatus,
+}
+
+func (s *Server) blogEntryComments(q url.Values) response {
+	id, _ := strconv.Atoi(q.Get("blogEntryId"))
+	if _, found := s.blogEntries[id]; !found {
+		return failed(fmt.Sprintf("blogEntryId: Blog entry with id %d not found", id))
+	}
+	return ok(nonNil(s.comments[id]))
+}
+
+func (s *Server) blogEntryView(q url.Values) response {
+	id, _ := strconv.Atoi(q.Get("blogEntryId"))
+	entry, found := s.blogEntries[id]
+	if !found {
+		return failed(fmt.Sprintf("blogEntryId: Blog entry with id %d not found", id))
+	}
+	return ok(entry)
+}
+
+func (s *Server) contestHacks(q url.Values) response {
+	id, resp := s.contestId(q)
+	if resp != nil {
+		return *resp
+	}
+	return ok(nonNil(s.hacks[id]))
+}
+
+func (s *Server) contestList(q url.Values) response {
+	return ok(nonNil(s.contests))
+}
+
+func (s *Server) contestRatingChanges(q url.Values) response {
+	id, resp := s.contestId(q)
+	if resp != nil {
+		return *resp
+	}
+	return ok(nonNil(s.ratingChanges[id]))
+}
+
+func (s *Server) contestStandings(q url.Values) response {
+	id, resp := s.contestId(q)
+	if resp != nil {
+		return *resp
+	}
+	standings := s.standings[id]
+	handles := handleSet(q.Get("handles"))
+	unofficial := q.Get("showUnofficial") == "true"
+	var types map[string]bool
+	if list := q.Get("participantTypes"); list != "" {
+		types = map[string]bool{}
+		for _, t := range strings.Split(list, ",") {
+			types[t] = true
+		}
+	}
+	room, _ := strconv.Atoi(q.Get("room"))
+	rows := []codeforces.Row{}
+	for _, row := range standings.Rows {
+		if !unofficial && row.Party.ParticipantType != "" && row.Party.ParticipantType != codeforces.ParticipantContestant {
+			continue
+		}
+		if types != nil && !types[row.Party.ParticipantType] {
+			continue
+		}
+		if room != 0 && row.Party.Room != room {
+			continue
+		}
+		if handles != nil && !hasMember(row.Party.Members, handles) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	standings.Rows = page(rows, q)
+	if standings.Problems == nil {
+		standings.Problems = []codeforces.Problem{}
+	}
+	return ok(standings)
+}
+
+func (s *Server) contestStatus(q url.Values) response {
+	id, resp := s.contestId(q)
+	if resp != nil {
+		return *resp
+	}
+	handles := handleSet(q.Get("handle"))
+	submissions := []codeforces.ContestStatus{}
+	for _, sub := range s.submissions[id] {
+		if handles == nil || hasMember(sub.Author.Members, handles) {
+			submissions = append(submissions, sub)
+		}
+	}
+	return ok(page(submissions, q))
+}
+
+func (s *Server) problemsetProblems(q url.Values) response {
+	var tags []string
+	if t := q.Get("tags"); t != "" {
+		tags = strings.Split(t, ";")
+	}
+	result := codeforces.Problemset{
+		Problems:          []codeforces.Problem{},
+		ProblemStatistics: []codeforces.ProblemStatistic{},
+	}
+	kept := map[string]bool{}
+	for _, p := range s.problemset.Problems {
+		if hasTags(p.Tags, tags) {
+			result.Problems = append(result.Problems, p)
+			kept[fmt.Sprint(p.ContestID, p.Index)] = true
+		}
+	}
+	for _, ps := range s.problemset.ProblemStatistics {
+		if kept[fmt.Sprint(ps.ContestID, ps.Index)] {
+			result.ProblemStatistics = append(result.ProblemStatistics, ps)
+		}
+	}
+	return ok(result)
+}
+
+// the latest submissions of every contest. Problemsets aren't modeled, so
+// problemsetName is ignored.
+func (s *Server) problemsetRecentStatus(q url.Values) response {
+	count, err := strconv.Atoi(q.Get("count"))
+	if err != nil || count < 1 || count > 1000 {
+		return failed("count: Field should contain value between 1 and 1000")
+	}
+	submissions := []codeforces.ContestStatus{}
+	for _, contest := range s.submissions {
+		submissions = append(submissions, contest...)
+	}
+	sort.Slice(submissions, func(i, j int) bool {
+		return submissions[i].ID > submissions[j].ID
+	})
+	if len(submissions) > count {
+		submissions = submissions[:count]
+	}
+	return ok(submissions)
+}
+
+func (s *Server) recentActionsList(q url.Values) response {
+	max, err := strconv.Atoi(q.Get("maxCount"))
+	if err != nil || max < 1 || max > 100 {
+		return failed("maxCount: Field should contain value between 1 and 100")
+	}
+	actions := nonNil(s.recentActions)
+	if len(actions) > max {
+		actions = actions[:max]
+	}
+	return ok(actions)
+}
+
+// entries written by a handle, most recent first, without their content
+func (s *Server) userBlogEntries(q url.Values) response {
+	handle := q.Get("handle")
+	if _, found := s.users[strings.ToLower(handle)]; !found {
+		return failed("handle: User with handle " + handle + " not found")
+	}
+	entries := []codeforces.BlogEntry{}
+	for _, e := range s.blogEntries {
+		if strings.EqualFold(e.AuthorHandle, handle) {
+			e.Content = ""
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID > entries[j].ID
+	})
+	return ok(entries)
+}
+
+func (s *Server) userFriends(q url.Values) response {
+	return ok(nonNil(s.friends[q.Get("apiKey")]))
+}
+
+func (s *Server) userInfo(q url.Values) response {
+	users := []codeforces.User{}
+	for _, handle := range strings.Split(q.Get("handles"), ";") {
+		u, found := s.users[strings.ToLower(handle)]
+		if !found {
+			return failed("handles: User with handle " + handle + " not found")
+		}
+		users = append(users, u)
+	}
+	return ok(users)
+}
+
+func (s *Server) userRating(q url.Values) response {
+	handle := q.Get("handle")
+	if _, found := s.users[strings.ToLower(handle)]; !found {
+		return failed("handle: User with handle " + handle + " not found")
+	}
+	return ok(nonNil(s.ratings[strings.ToLower(handle)]))
+}
+
+// users with a rating, by decreasing rating. Users are active when they were
+// online during the last month and retired when they weren't during the last
+// year, users without lastOnlineTimeSeconds are neither. A contestId keeps the
+// users with a rating change in that contest.
+func (s *Server) userRatedList(q url.Values) response {
+	var participants map[string]bool
+	if q.Has("contestId") {
+		id, resp := s.contestId(q)
+		if resp != nil {
+			return *resp
+		}
+		participants = map[string]bool{}
+		for _, rc := range s.ratingChanges[id] {
+			participants[strings.ToLower(rc.Handle)] = true
+		}
+	}
+	now := time.Now()
+	activeOnly := q.Get("activeOnly") == "true"
+	includeRetired := q.Get("includeRetired") == "true"
+	users := []codeforces.User{}
+	for handle, u := range s.users {
+		if !u.IsRated() || participants != nil && !participants[handle] {
+			continue
+		}
+		lastOnline := time.Unix(int64(u.LastOnlineTimeSeconds), 0)
+		if u.LastOnlineTimeSeconds != 0 {
+			if activeOnly && now.Sub(lastOnline) > 30*24*time.Hour {
+				continue
+			}
+			if !includeRetired && now.Sub(lastOnline) > 365*24*time.Hour {
+				continue
+			}
+		}
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].Rating != users[j].Rating {
+			return users[i].Rating > users[j].Rating
+		}
+		return users[i].Handle < users[j].Handle
+	})
+	return ok(users)
+}
+
+// submissions of a handle across every contest, most recent first
+func (s *Server) userStatus(q url.Values) response {
+	handle := q.Get("handle")
+	if _, found := s.users[strings.ToLower(handle)]; !found {
+		return failed("handle: User with handle " + handle + " not found")
+	}
+	handles := handleSet(handle)
+	submissions := []codeforces.ContestStatus{}
+	for _, contest := range s.submissions {
+		for _, sub := range contest {
+			if hasMember(sub.Author.Members, handles) {
+				submissions = append(submissions, sub)
+			}
+		}
+	}
+	sort.Slice(submissions, func(i, j int) bool {
+		return submissions[i].ID > submissions[j].ID
+	})
+	return ok(page(submissions, q))
+}
+
+// parses contestId, failing like the api does for unknown contests
+func (s *Server) contestId(q url.Values) (int, *response) {
+	id, err := strconv.Atoi(q.Get("contestId"))
+	if err != nil {
+		resp := failed("contestId: Field should contain long integer value")
+		return 0, &resp
+	}
+	for _, c := range s.contests {
+		if c.ID == id {
+			return id, nil
+		}
+	}
+	if _, found := s.standings[id]; found {
+		return id, nil
+	}
+	if _, found := s.submissions[id]; found {
+		return id, nil
+	}
+	if _, found := s.ratingChanges[id]; found {
+		return id, nil
+	}
+	resp := failed(fmt.Sprintf("contestId: Contest with id %d not found", id))
+	return 0, &resp
+}
+
+// applies the 1-based from and count parameters, both optional
+func page[T any](items []T, q url.Values) []T {
+	from, err := strconv.Atoi(q.Get("from"))
+	if err != nil || from < 1 {
+		from = 1
+	}
+	if from > len(items) {
+		return []T{}
+	}
+	items = items[from-1:]
+	if count, err := strconv.Atoi(q.Get("count")); err == nil && count >= 0 && count < len(items) {
+		items = items[:count]
+	}
+	return items
+}
+
+// lower cased handles of a ;-separated list, nil when empty
+func handleSet(list string) map[string]bool {
+	if list == "" {
+		return nil
+	}
+	set := map[string]bool{}
+	for _, h := range strings.Split(list, ";") {
+		set[strings.ToLower(h)] = true
+	}
+	return set
+}
+
+func hasMember(members []codeforces.Member, handles map[string]bool) bool {
+	for _, m := range members {
+		if handles[strings.ToLower(m.Handle)] {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTags(problemTags, wanted []string) bool {
+	have := map[string]bool{}
+	for _, t := range problemTags {
+		have[t] = true
+	}
+	for _, t := range wanted {
+		if !have[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// empty results are encoded as [] rather than null, like the api does
+func nonNil[S ~[]T, T any](s S) S {
+	if s == nil {
+		return S{}
+	}
+	return s
+}
@@ -0,0 +1,294 @@
+package cftest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/michelececcacci/codeforces"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserInfo(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.AddUsers(codeforces.User{Handle: "tourist", Rating: 3803}, codeforces.User{Handle: "Benq", Rating: 3700})
+	c := s.Client("", "")
+	resp, err := c.User.Info([]string{"benq", "tourist"})
+	assert.Nil(t, err)
+	assert.Len(t, *resp, 2)
+	assert.Equal(t, "Benq", (*resp)[0].Handle)
+	assert.Equal(t, 3803, (*resp)[1].Rating)
+
+	resp, err = c.User.Info([]string{"tourist", "nobody"})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, codeforces.ErrHandleNotFound))
+	assert.Equal(t, 2, s.Calls("user.info"))
+}
+
+func TestContestStandings(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	row := func(rank int, handle, participantType string) codeforces.Row {
+		return codeforces.Row{
+			Rank:  rank,
+			Party: codeforces.Party{Members: []codeforces.Member{{Handle: handle}}, ParticipantType: participantType},
+		}
+	}
+	s.SetStandings(566, codeforces.ContestStandings{
+		Contest: codeforces.Contest{Name: "VK Cup 2015 - Finals, online mirror", Phase: "FINISHED"},
+		Rows: []codeforces.Row{
+			row(1, "rng_58", "CONTESTANT"),
+			row(2, "ngfam_kongu", "CONTESTANT"),
+			{Rank: 3, Party: codeforces.Party{Members: []codeforces.Member{{Handle: "tourist"}}, ParticipantType: "CONTESTANT", Room: 2}},
+			row(0, "virtual", "VIRTUAL"),
+		},
+	})
+	c := s.Client("", "")
+	resp, err := c.Contest.Standings(566, 2, 5, nil, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 566, resp.Contest.ID)
+	assert.Len(t, resp.Rows, 2)
+	assert.Equal(t, 2, resp.Rows[0].Rank)
+
+	resp, err = c.Contest.Standings(566, 1, 10, []string{"tourist", "virtual"}, true)
+	assert.Nil(t, err)
+	assert.Len(t, resp.Rows, 2)
+
+	resp, err = c.Contest.StandingsWithOptions(566, codeforces.StandingsOptions{
+		ShowUnofficial:   true,
+		ParticipantTypes: []string{codeforces.ParticipantVirtual},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, resp.Rows, 1)
+	assert.Equal(t, "virtual", resp.Rows[0].Party.Members[0].Handle)
+
+	resp, err = c.Contest.StandingsWithOptions(566, codeforces.StandingsOptions{Room: 2})
+	assert.Nil(t, err)
+	assert.Len(t, resp.Rows, 1)
+	assert.Equal(t, "tourist", resp.Rows[0].Party.Members[0].Handle)
+
+	_, err = c.Contest.StandingsWithOptions(566, codeforces.StandingsOptions{AsManager: true})
+	assert.True(t, errors.Is(err, codeforces.ErrAuthRequired))
+
+	list, err := c.Contest.List(false)
+	assert.Nil(t, err)
+	assert.Len(t, *list, 1)
+
+	_, err = c.Contest.Standings(1, 1, 10, nil, false)
+	assert.True(t, errors.Is(err, codeforces.ErrContestNotFound))
+}
+
+func TestContestStatus(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	author := func(handle string) codeforces.Author {
+		return codeforces.Author{Members: []codeforces.Member{{Handle: handle}}}
+	}
+	s.AddSubmissions(566,
+		codeforces.ContestStatus{ID: 1, Author: author("tourist")},
+		codeforces.ContestStatus{ID: 2, Author: author("benq")},
+		codeforces.ContestStatus{ID: 3, Author: author("tourist")},
+	)
+	c := s.Client("", "")
+	resp, err := c.Contest.StatusWithHandle(566, 1, 10, "tourist")
+	assert.Nil(t, err)
+	assert.Len(t, *resp, 2)
+	assert.Equal(t, 3, (*resp)[1].ID)
+
+	p := c.Contest.AllStatus(566)
+	p.PageSize = 2
+	all, err := p.All()
+	assert.Nil(t, err)
+	assert.Len(t, all, 3)
+}
+
+func TestUserStatus(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.AddUsers(codeforces.User{Handle: "tourist"})
+	author := codeforces.Author{Members: []codeforces.Member{{Handle: "tourist"}}}
+	s.AddSubmissions(566, codeforces.ContestStatus{ID: 1, Author: author}, codeforces.ContestStatus{ID: 4, Author: author})
+	s.AddSubmissions(1800, codeforces.ContestStatus{ID: 3, Author: author}, codeforces.ContestStatus{ID: 2})
+	c := s.Client("", "")
+	resp, err := c.User.Status("tourist", 1, 2)
+	assert.Nil(t, err)
+	assert.Len(t, *resp, 2)
+	assert.Equal(t, 4, (*resp)[0].ID)
+	assert.Equal(t, 3, (*resp)[1].ID)
+
+	all, err := c.User.AllStatus("tourist").All()
+	assert.Nil(t, err)
+	assert.Len(t, all, 3)
+
+	_, err = c.User.Status("nobody", 1, 10)
+	assert.True(t, errors.Is(err, codeforces.ErrHandleNotFound))
+}
+
+func TestRatedList(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	now := int(time.Now().Unix())
+	s.AddUsers(
+		codeforces.User{Handle: "tourist", Rating: 3803, LastOnlineTimeSeconds: now},
+		codeforces.User{Handle: "Benq", Rating: 3725, LastOnlineTimeSeconds: now - 60*24*3600},
+		codeforces.User{Handle: "retired", Rating: 2000, LastOnlineTimeSeconds: now - 2*365*24*3600},
+		codeforces.User{Handle: "unrated"},
+	)
+	s.SetRatingChanges(1800, codeforces.RatingChange{Handle: "Benq"})
+	c := s.Client("", "")
+	handles := func(users *[]codeforces.User) []string {
+		out := []string{}
+		for _, u := range *users {
+			out = append(out, u.Handle)
+		}
+		return out
+	}
+	resp, err := c.User.RatedList(false, false, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"tourist", "Benq"}, handles(resp))
+	resp, err = c.User.RatedList(true, false, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"tourist"}, handles(resp))
+	resp, err = c.User.RatedList(false, true, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"tourist", "Benq", "retired"}, handles(resp))
+	resp, err = c.User.RatedList(false, false, 1800)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Benq"}, handles(resp))
+}
+
+func TestBlogEntries(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.AddUsers(codeforces.User{Handle: "MikeMirzayanov"})
+	s.AddBlogEntries(
+		codeforces.BlogEntry{ID: 123, AuthorHandle: "MikeMirzayanov", Content: "<p>maintenance</p>"},
+		codeforces.BlogEntry{ID: 115892, AuthorHandle: "MikeMirzayanov", Content: "<p>round</p>"},
+		codeforces.BlogEntry{ID: 1, AuthorHandle: "tourist"},
+	)
+	c := s.Client("", "")
+	entries, err := c.User.BlogEntries("mikemirzayanov")
+	assert.Nil(t, err)
+	assert.Len(t, *entries, 2)
+	assert.Equal(t, 115892, (*entries)[0].ID)
+	assert.Empty(t, (*entries)[0].Content)
+
+	full, err := c.Blog.FullEntries(*entries)
+	assert.Nil(t, err)
+	assert.Equal(t, "<p>round</p>", (*full)[0].Content)
+	assert.Equal(t, "<p>maintenance</p>", (*full)[1].Content)
+}
+
+func TestRecentStatus(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.AddSubmissions(566, codeforces.ContestStatus{ID: 1}, codeforces.ContestStatus{ID: 3})
+	s.AddSubmissions(1800, codeforces.ContestStatus{ID: 2})
+	resp, err := s.Client("", "").Problems.RecentStatus(2, "")
+	assert.Nil(t, err)
+	assert.Len(t, *resp, 2)
+	assert.Equal(t, 3, (*resp)[0].ID)
+	assert.Equal(t, 2, (*resp)[1].ID)
+}
+
+func TestSignatureVerification(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.AddCredentials("key", "secret")
+	s.SetFriends("key", "tourist", "benq")
+
+	resp, err := s.Client("key", "secret").User.Friends(false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"tourist", "benq"}, *resp)
+
+	_, err = s.Client("key", "wrong").User.Friends(false)
+	assert.True(t, errors.Is(err, codeforces.ErrUnauthorized))
+
+	_, err = s.Client("unknown", "secret").User.Friends(false)
+	assert.True(t, errors.Is(err, codeforces.ErrUnauthorized))
+}
+
+func TestFailuresAndCallLimit(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.AddUsers(codeforces.User{Handle: "tourist"})
+	s.FailNext("user.info", "Internal Server Error")
+	c := s.Client("", "")
+	_, err := c.User.Info([]string{"tourist"})
+	var apiErr *codeforces.APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "Internal Server Error", apiErr.Comment)
+
+	s.ExceedCallLimit(1)
+	_, err = c.User.Info([]string{"tourist"})
+	assert.True(t, errors.Is(err, codeforces.ErrCallLimitExceeded))
+
+	s.ExceedCallLimit(2)
+	retrying := s.Client("", "", codeforces.WithRetryPolicy(&codeforces.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+	resp, err := retrying.User.Info([]string{"tourist"})
+	assert.Nil(t, err)
+	assert.Len(t, *resp, 1)
+}
+
+func TestLoadFixture(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	assert.Nil(t, s.LoadFixture("user.rating", "../testdata/user/rating/userrating.json"))
+	resp, err := s.Client("", "").User.Rating("tourist")
+	assert.Nil(t, err)
+	assert.Len(t, *resp, 2)
+	assert.NotNil(t, s.LoadFixture("user.info", "missing.json"))
+}
+
+func TestProblemsetTags(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetProblemset(codeforces.Problemset{
+		Problems: []codeforces.Problem{
+			{ContestID: 1, Index: "A", Tags: []string{"math", "greedy"}},
+			{ContestID: 1, Index: "B", Tags: []string{"graphs"}},
+		},
+		ProblemStatistics: []codeforces.ProblemStatistic{
+			{ContestID: 1, Index: "A", SolvedCount: 10},
+			{ContestID: 1, Index: "B", SolvedCount: 5},
+		},
+	})
+	resp, err := s.Client("", "").Problems.Problemset([]string{"math"})
+	assert.Nil(t, err)
+	assert.Len(t, resp.Problems, 1)
+	assert.Len(t, resp.ProblemStatistics, 1)
+	assert.Equal(t, 10, resp.ProblemStatistics[0].SolvedCount)
+}
+
+func TestManagerStandingsNotCached(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.AddCredentials("key", "secret")
+	s.SetStandings(566, codeforces.ContestStandings{
+		Contest: codeforces.Contest{Phase: "FINISHED"},
+		Rows:    []codeforces.Row{{Rank: 1, Party: codeforces.Party{Members: []codeforces.Member{{Handle: "tourist"}}}}},
+	})
+	cache := codeforces.NewMemoryCache(10)
+	client := func(apiKey, apiSecret string) *codeforces.Client {
+		tr := codeforces.NewTransport(apiKey, apiSecret,
+			codeforces.WithBaseURL(s.URL()),
+			codeforces.WithRateLimiter(nil),
+			codeforces.WithRetryPolicy(nil),
+		)
+		return codeforces.NewCustomClient(codeforces.NewCachingTransport(tr, cache))
+	}
+	manager, anonymous := client("key", "secret"), client("", "")
+	opts := codeforces.StandingsOptions{AsManager: true}
+
+	resp, err := manager.Contest.StandingsWithOptions(566, opts)
+	assert.Nil(t, err)
+	assert.Len(t, resp.Rows, 1)
+	_, err = manager.Contest.StandingsWithOptions(566, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, s.Calls("contest.standings"))
+
+	_, err = anonymous.Contest.StandingsWithOptions(566, opts)
+	assert.True(t, errors.Is(err, codeforces.ErrAuthRequired))
+	assert.Equal(t, 2, s.Calls("contest.standings"))
+}
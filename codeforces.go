@@ -4,10 +4,11 @@
 package codeforces
 
 import (
-	"crypto/sha512"
+	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
-	"net/url"
+	"strings"
 	"time"
 )
 
@@ -26,60 +27,202 @@ type Client struct {
 	Actions  *actionsService
 }
 
-func NewClient(apiKey, apiSecret string) *Client {
-	c := newDefaultClientWrapper(defaultbaseURLString, apiKey, apiSecret)
-	return NewCustomClient(apiKey, apiSecret, c)
+// by default every client gets its own rate limiter, allowing one call every
+// DefaultCallInterval, and retries transient failures following
+// DefaultRetryPolicy. Both can be changed through options.
+func NewClient(apiKey, apiSecret string, opts ...ClientOption) *Client {
+	return NewCustomClient(NewTransport(apiKey, apiSecret, opts...))
 }
 
-func NewCustomClient(apiKey, apiSecret string, c *httpClientWrapper) *Client {
+// builds a client on top of an arbitrary transport, e.g. one returned by
+// NewTransport and wrapped in a caching or recording layer
+func NewCustomClient(t Transport) *Client {
 	return &Client{
-		Blog:    &blogService{c},
-		User:    &userService{c},
-		Contest: &contestService{c},
-		Actions: &actionsService{c},
+		Blog:     &blogService{t},
+		User:     &userService{t},
+		Contest:  &contestService{t},
+		Problems: &problemService{t},
+		Actions:  &actionsService{t},
+	}
+}
+
+// returns the transport used by NewClient: calls are signed, rate limited
+// and retried according to opts. Identical calls made at the same time are
+// collapsed into one, unless disabled with WithDeduplication. Middlewares
+// added with WithMiddleware wrap all of the above.
+func NewTransport(apiKey, apiSecret string, opts ...ClientOption) Transport {
+	c := newDefaultClientWrapper(defaultbaseURLString, apiKey, apiSecret)
+	c.limiter = NewRateLimiter(DefaultCallInterval)
+	c.retry = DefaultRetryPolicy()
+	c.dedup = true
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.timeout > 0 {
+		if c.http.client == nil {
+			c.http.client = http.DefaultClient
+		}
+		hc := *c.http.client
+		hc.Timeout = c.timeout
+		c.http.client = &hc
+	}
+	var t Transport = c
+	if c.dedup {
+		t = newDedupTransport(c)
+	}
+	t = Chain(t, c.middlewares...)
+	if c.lang != "" {
+		t = &languageTransport{next: t, lang: c.lang}
+	}
+	return t
+}
+
+// adds the default language of the client to the calls that don't set one. It
+// sits on top of every other layer, so that middlewares and deduplication tell
+// languages apart. Layers wrapping it, such as a CachingTransport, get the
+// default through defaultLanguage.
+type languageTransport struct {
+	next Transport
+	lang string
+}
+
+func (t *languageTransport) Get(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+	if _, ok := params["lang"]; !ok {
+		params = withLanguage(params, t.lang)
 	}
+	return t.next.Get(ctx, method, params)
 }
 
+func (t *languageTransport) defaultLanguage() string {
+	return t.lang
+}
+
+// takes care of everything that happens around a single attempt: rate limiting,
+// retries and logging. The attempt itself is delegated to transport.
 type httpClientWrapper struct {
-	client        *http.Client
-	baseUrlString string
-	apiKey        string
-	apiSecret     string
+	transport Transport
+	http      *httpTransport
+	limiter   *RateLimiter
+	retry     *RetryPolicy
+	timeout   time.Duration
+	lang      string
+	logger    *slog.Logger
+	dedup     bool
+	// applied around the whole call, retries included
+	middlewares []Middleware
+	// records the rate limiter wait time, nil when disabled
+	metrics *Metrics
 }
 
 func newDefaultClientWrapper(baseUrlString, apiKey, apiSecret string) *httpClientWrapper {
+	h := &httpTransport{
+		endpoints:        newEndpoints(baseUrlString),
+		failoverCooldown: DefaultFailoverCooldown,
+		client:           http.DefaultClient,
+	}
 	return &httpClientWrapper{
-		baseUrlString: baseUrlString,
-		apiKey:        apiKey,
-		client:        http.DefaultClient,
-		apiSecret:     apiSecret,
+		http:      h,
+		transport: &SigningTransport{Next: h, Signer: Signer{Key: apiKey, Secret: apiSecret}},
 	}
 }
 
-func (c *httpClientWrapper) Get(suffix string, userParams map[string]string) (*http.Response, error) {
-	base, err := url.Parse(c.baseUrlString + suffix)
-	if err != nil {
-		return nil, err
-	}
-	params := url.Values{}
+// performs a signed GET request for the given api method. The request is bound
+// to ctx, so cancelling it aborts the call. Failed attempts are retried
+// according to the retry policy, and responses with a status other than 200
+// are turned into errors.
+func (c *httpClientWrapper) Get(ctx context.Context, suffix string, userParams map[string]string) (*http.Response, error) {
+	params := make(map[string]string, len(userParams)+1)
 	for k, v := range userParams {
-		params.Add(k, v)
+		params[k] = v
+	}
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		resp, err := c.get(ctx, suffix, params)
+		c.log(ctx, slog.LevelDebug, "codeforces api call",
+			slog.String("method", suffix),
+			slog.Any("params", params),
+			slog.Int("attempt", attempt),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("host", host(resp)),
+			slog.Any("error", err),
+		)
+		if err == nil {
+			return resp, nil
+		}
+		if !c.retry.shouldRetry(attempt, err) {
+			return nil, err
+		}
+		c.log(ctx, slog.LevelWarn, "codeforces api call failed, retrying",
+			slog.String("method", suffix),
+			slog.Int("attempt", attempt),
+			slog.Any("error", err),
+		)
+		if err := c.retry.sleep(ctx, attempt); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// a single attempt, signed with the current time by the underlying transport
+func (c *httpClientWrapper) get(ctx context.Context, suffix string, params map[string]string) (*http.Response, error) {
+	if p, ok := c.transport.(preflighter); ok {
+		if err := p.preflight(suffix, params); err != nil {
+			return nil, err
+		}
+	}
+	if c.limiter != nil {
+		start := time.Now()
+		err := c.limiter.Wait(ctx)
+		c.metrics.observeWait(time.Since(start))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.transport.Get(ctx, suffix, params)
+}
+
+func (c *httpClientWrapper) log(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	if c.logger != nil {
+		c.logger.LogAttrs(ctx, level, msg, attrs...)
 	}
-	params.Add("apiKey", c.apiKey)
-	t := fmt.Sprint(time.Now().UTC().UnixMilli() / 1000)
-	params.Add("time", t)
-	oldParams := params.Encode()
-	randomPrefix := fmt.Sprint(randomInRange(1e5, 1e6))
-	text := (randomPrefix + "/" + suffix + "?" + oldParams + "#" + c.apiSecret)
-	hash := sha512.Sum512([]byte(text))
-	params.Add("apiSig", randomPrefix+fmt.Sprintf("%x", hash))
-	base.RawQuery = params.Encode()
-	resp, err := c.client.Get(base.String())
-	return resp, err
 }
 
 type service struct {
-	client *httpClientWrapper
+	client Transport
+}
+
+type languageKey struct{}
+
+// makes the calls made with ctx use lang (e.g. "en" or "ru"), overriding the
+// client default set with WithLanguage
+func ContextWithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+func LanguageFromContext(ctx context.Context) string {
+	lang, _ := ctx.Value(languageKey{}).(string)
+	return lang
+}
+
+// performs a call on t, adding the language of ctx to params. It's done before
+// reaching the transport, so that layers such as caching tell languages apart.
+// The default language set with WithLanguage is only added by the transport
+// returned by NewTransport, see NewCachingTransport for caching on top of it.
+func call(ctx context.Context, t Transport, method string, params map[string]string) (*http.Response, error) {
+	if lang := LanguageFromContext(ctx); lang != "" {
+		params = withLanguage(params, lang)
+	}
+	return t.Get(ctx, method, params)
+}
+
+// returns a copy of params using lang
+func withLanguage(params map[string]string, lang string) map[string]string {
+	withLang := make(map[string]string, len(params)+1)
+	for k, v := range params {
+		withLang[k] = v
+	}
+	withLang["lang"] = lang
+	return withLang
 }
 
 type (
@@ -91,60 +234,181 @@ type (
 )
 
 func (s *blogService) Comments(id uint) (*[]Comment, error) {
+	return s.CommentsCtx(context.Background(), id)
+}
+
+func (s *blogService) CommentsCtx(ctx context.Context, id uint) (*[]Comment, error) {
 	params := map[string]string{"blogEntryId": fmt.Sprint(id)}
-	resp, err := s.client.Get("blogEntry.comments", params)
+	resp, err := call(ctx, s.client, "blogEntry.comments", params)
 	return serializeResponse[[]Comment](resp, err)
 }
 
 func (s *blogService) EntryById(id uint) (*BlogEntry, error) {
+	return s.EntryByIdCtx(context.Background(), id)
+}
+
+func (s *blogService) EntryByIdCtx(ctx context.Context, id uint) (*BlogEntry, error) {
 	params := map[string]string{"blogEntryId": fmt.Sprint(id)}
-	resp, err := s.client.Get("blogEntry.view", params)
+	resp, err := call(ctx, s.client, "blogEntry.view", params)
 	return serializeResponse[BlogEntry](resp, err)
 }
 
+// fetches the full version of entries, content included, such as the ones
+// returned by userService.BlogEntries. One call to EntryById is made per entry.
+func (s *blogService) FullEntries(entries []BlogEntry) (*[]BlogEntry, error) {
+	return s.FullEntriesCtx(context.Background(), entries)
+}
+
+func (s *blogService) FullEntriesCtx(ctx context.Context, entries []BlogEntry) (*[]BlogEntry, error) {
+	full := make([]BlogEntry, 0, len(entries))
+	for _, e := range entries {
+		entry, err := s.EntryByIdCtx(ctx, uint(e.ID))
+		if err != nil {
+			return nil, err
+		}
+		full = append(full, *entry)
+	}
+	return &full, nil
+}
+
 func (s *contestService) Hacks(id uint) (*ContestHack, error) {
+	return s.HacksCtx(context.Background(), id)
+}
+
+func (s *contestService) HacksCtx(ctx context.Context, id uint) (*ContestHack, error) {
 	params := map[string]string{"contestId": fmt.Sprint(id)}
-	resp, err := s.client.Get("contest.hacks", params)
+	resp, err := call(ctx, s.client, "contest.hacks", params)
 	return serializeResponse[ContestHack](resp, err)
 }
 
 func (s *contestService) RatingChange(id uint) (*[]RatingChange, error) {
+	return s.RatingChangeCtx(context.Background(), id)
+}
+
+func (s *contestService) RatingChangeCtx(ctx context.Context, id uint) (*[]RatingChange, error) {
 	params := map[string]string{"contestId": fmt.Sprint(id)}
-	resp, err := s.client.Get("contest.ratingChanges", params)
+	resp, err := call(ctx, s.client, "contest.ratingChanges", params)
 	return serializeResponse[[]RatingChange](resp, err)
 }
 
 func (s *contestService) List(gym bool) (*[]Contest, error) {
+	return s.ListCtx(context.Background(), gym)
+}
+
+func (s *contestService) ListCtx(ctx context.Context, gym bool) (*[]Contest, error) {
 	params := map[string]string{"gym": fmt.Sprint(gym)}
-	resp, err := s.client.Get("contest.list", params)
+	resp, err := call(ctx, s.client, "contest.list", params)
 	return serializeResponse[[]Contest](resp, err)
 }
 
 func (s *userService) Info(users []string) (*[]User, error) {
+	return s.InfoCtx(context.Background(), users)
+}
+
+func (s *userService) InfoCtx(ctx context.Context, users []string) (*[]User, error) {
 	params := map[string]string{"handles": encodeToParameter(users)}
-	resp, err := s.client.Get("user.info", params)
-	err = handleResponseStatusCode(resp, err)
+	resp, err := call(ctx, s.client, "user.info", params)
 	return serializeResponse[[]User](resp, err)
 }
 
 func (s *userService) Rating(user string) (*[]RatingChange, error) {
+	return s.RatingCtx(context.Background(), user)
+}
+
+func (s *userService) RatingCtx(ctx context.Context, user string) (*[]RatingChange, error) {
 	params := map[string]string{"handle": user}
-	resp, err := s.client.Get("user.rating", params)
+	resp, err := call(ctx, s.client, "user.rating", params)
 	return serializeResponse[[]RatingChange](resp, err)
 }
 
 func (s *contestService) Standings(contestId, from, count uint, handles []string, unofficial bool) (*ContestStandings, error) {
+	return s.StandingsCtx(context.Background(), contestId, from, count, handles, unofficial)
+}
+
+func (s *contestService) StandingsCtx(ctx context.Context, contestId, from, count uint, handles []string, unofficial bool) (*ContestStandings, error) {
+	return s.StandingsWithOptionsCtx(ctx, contestId, StandingsOptions{
+		From:           from,
+		Count:          count,
+		Handles:        handles,
+		ShowUnofficial: unofficial,
+	})
+}
+
+// participant types, as found in Party.ParticipantType
+const (
+	ParticipantContestant       = "CONTESTANT"
+	ParticipantPractice         = "PRACTICE"
+	ParticipantVirtual          = "VIRTUAL"
+	ParticipantManager          = "MANAGER"
+	ParticipantOutOfCompetition = "OUT_OF_COMPETITION"
+)
+
+// StandingsOptions holds the parameters of contest.standings. Zero values are
+// left out of the call.
+type StandingsOptions struct {
+	// 1-based index of the first row
+	From  uint
+	Count uint
+	// only the rows of parties with one of these members
+	Handles        []string
+	ShowUnofficial bool
+	// only the rows of these participant types, e.g. ParticipantContestant.
+	// Unofficial types need ShowUnofficial as well.
+	ParticipantTypes []string
+	// only the rows of a room
+	Room uint
+	// the standings as seen by a manager of the contest, which requires
+	// authentication
+	AsManager bool
+}
+
+func (o *StandingsOptions) params(contestId uint) map[string]string {
 	params := map[string]string{
 		"contestId":      fmt.Sprint(contestId),
-		"from":           fmt.Sprint(from),
-		"count":          fmt.Sprint(count),
-		"handles":        encodeToParameter(handles),
-		"showUnofficial": fmt.Sprint(unofficial),
+		"showUnofficial": fmt.Sprint(o.ShowUnofficial),
+	}
+	if o.From != 0 {
+		params["from"] = fmt.Sprint(o.From)
+	}
+	if o.Count != 0 {
+		params["count"] = fmt.Sprint(o.Count)
+	}
+	if len(o.Handles) > 0 {
+		params["handles"] = encodeToParameter(o.Handles)
+	}
+	if len(o.ParticipantTypes) > 0 {
+		params["participantTypes"] = strings.Join(o.ParticipantTypes, ",")
+	}
+	if o.Room != 0 {
+		params["room"] = fmt.Sprint(o.Room)
 	}
-	resp, err := s.client.Get("contest.standings", params)
+	if o.AsManager {
+		params["asManager"] = "true"
+	}
+	return params
+}
+
+func (s *contestService) StandingsWithOptions(contestId uint, opts StandingsOptions) (*ContestStandings, error) {
+	return s.StandingsWithOptionsCtx(context.Background(), contestId, opts)
+}
+
+func (s *contestService) StandingsWithOptionsCtx(ctx context.Context, contestId uint, opts StandingsOptions) (*ContestStandings, error) {
+	resp, err := call(ctx, s.client, "contest.standings", opts.params(contestId))
 	return serializeResponse[ContestStandings](resp, err)
 }
 
+// pages through the rows of the standings. Contest and problems are the same
+// for every page, so only the rows are returned.
+func (s *contestService) StandingsPages(contestId uint, handles []string, unofficial bool) *Paginator[Row] {
+	return newPaginator(func(ctx context.Context, from, count uint) ([]Row, error) {
+		resp, err := s.StandingsCtx(ctx, contestId, from, count, handles, unofficial)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Rows, nil
+	})
+}
+
 func statusDefaultParams(contestId, from, count uint) *map[string]string {
 	params := map[string]string{
 		"contestId": fmt.Sprint(contestId),
@@ -155,37 +419,207 @@ func statusDefaultParams(contestId, from, count uint) *map[string]string {
 }
 
 func (s *contestService) StatusWithHandle(contestId, from, count uint, handle string) (*[]ContestStatus, error) {
+	return s.StatusWithHandleCtx(context.Background(), contestId, from, count, handle)
+}
+
+func (s *contestService) StatusWithHandleCtx(ctx context.Context, contestId, from, count uint, handle string) (*[]ContestStatus, error) {
 	params := statusDefaultParams(contestId, from, count)
 	(*params)["handle"] = handle
-	resp, err := s.client.Get("contest.status", *params)
+	resp, err := call(ctx, s.client, "contest.status", *params)
 	return serializeResponse[[]ContestStatus](resp, err)
 }
 
 func (s *contestService) Status(contestId, from, count uint) (*[]ContestStatus, error) {
-	resp, err := s.client.Get("contest.status", *statusDefaultParams(contestId, from, count))
+	return s.StatusCtx(context.Background(), contestId, from, count)
+}
+
+func (s *contestService) StatusCtx(ctx context.Context, contestId, from, count uint) (*[]ContestStatus, error) {
+	resp, err := call(ctx, s.client, "contest.status", *statusDefaultParams(contestId, from, count))
 	return serializeResponse[[]ContestStatus](resp, err)
 }
 
+// pages through every submission of a contest
+func (s *contestService) AllStatus(contestId uint) *Paginator[ContestStatus] {
+	return newPaginator(func(ctx context.Context, from, count uint) ([]ContestStatus, error) {
+		resp, err := s.StatusCtx(ctx, contestId, from, count)
+		if err != nil {
+			return nil, err
+		}
+		return *resp, nil
+	})
+}
+
+// pages through every submission of handle in a contest
+func (s *contestService) AllStatusWithHandle(contestId uint, handle string) *Paginator[ContestStatus] {
+	return newPaginator(func(ctx context.Context, from, count uint) ([]ContestStatus, error) {
+		resp, err := s.StatusWithHandleCtx(ctx, contestId, from, count, handle)
+		if err != nil {
+			return nil, err
+		}
+		return *resp, nil
+	})
+}
+
+// calls fn for every submission as soon as it's decoded, so that even whole
+// contests can be scanned without holding all of their submissions in memory.
+// A count of 0 streams every submission starting from from. Iteration stops
+// at the first error returned by fn, which is then returned.
+func (s *contestService) StatusEach(contestId, from, count uint, fn func(ContestStatus) error) error {
+	return s.StatusEachCtx(context.Background(), contestId, from, count, fn)
+}
+
+func (s *contestService) StatusEachCtx(ctx context.Context, contestId, from, count uint, fn func(ContestStatus) error) error {
+	params := statusDefaultParams(contestId, from, count)
+	if count == 0 {
+		delete(*params, "count")
+	}
+	resp, err := call(ctx, s.client, "contest.status", *params)
+	return streamResponse(resp, err, fn)
+}
+
 // tags can also be empty (will return every problem in the problemset)
 func (s *problemService) Problemset(tags []string) (*Problemset, error) {
+	return s.ProblemsetCtx(context.Background(), tags)
+}
+
+func (s *problemService) ProblemsetCtx(ctx context.Context, tags []string) (*Problemset, error) {
 	params := map[string]string{"tags": encodeToParameter(tags)}
-	resp, err := s.client.Get("problemset.problems", params)
+	resp, err := call(ctx, s.client, "problemset.problems", params)
 	return serializeResponse[Problemset](resp, err)
 }
 
+// Maximum count can be up to 1000. problemsetName can be empty, or the name
+// of a problemset such as "acmsguru"
+func (s *problemService) RecentStatus(count uint, problemsetName string) (*[]ContestStatus, error) {
+	return s.RecentStatusCtx(context.Background(), count, problemsetName)
+}
+
+func (s *problemService) RecentStatusCtx(ctx context.Context, count uint, problemsetName string) (*[]ContestStatus, error) {
+	if count > 1000 {
+		return nil, fmt.Errorf("Count is greater than 1000")
+	}
+	params := map[string]string{"count": fmt.Sprint(count)}
+	if problemsetName != "" {
+		params["problemsetName"] = problemsetName
+	}
+	resp, err := call(ctx, s.client, "problemset.recentStatus", params)
+	return serializeResponse[[]ContestStatus](resp, err)
+}
+
 // Maximum count can be up to 100
 func (s *actionsService) RecentActions(count uint) (*[]RecentAction, error) {
+	return s.RecentActionsCtx(context.Background(), count)
+}
+
+func (s *actionsService) RecentActionsCtx(ctx context.Context, count uint) (*[]RecentAction, error) {
 	if count > 100 {
 		return nil, fmt.Errorf("Count is greater than 100")
 	}
 	params := map[string]string{"maxCount": fmt.Sprint(count)}
-	resp, err := s.client.Get("recentActions", params)
+	resp, err := call(ctx, s.client, "recentActions", params)
 	return serializeResponse[[]RecentAction](resp, err)
 }
 
-// Requires authentication
+// returns the submissions of handle across every contest, most recent first
+func (s *userService) Status(handle string, from, count uint) (*[]ContestStatus, error) {
+	return s.StatusCtx(context.Background(), handle, from, count)
+}
+
+func (s *userService) StatusCtx(ctx context.Context, handle string, from, count uint) (*[]ContestStatus, error) {
+	params := map[string]string{
+		"handle": handle,
+		"from":   fmt.Sprint(from),
+		"count":  fmt.Sprint(count),
+	}
+	resp, err := call(ctx, s.client, "user.status", params)
+	return serializeResponse[[]ContestStatus](resp, err)
+}
+
+// pages through every submission of handle
+func (s *userService) AllStatus(handle string) *Paginator[ContestStatus] {
+	return newPaginator(func(ctx context.Context, from, count uint) ([]ContestStatus, error) {
+		resp, err := s.StatusCtx(ctx, handle, from, count)
+		if err != nil {
+			return nil, err
+		}
+		return *resp, nil
+	})
+}
+
+// calls fn for every submission of handle as soon as it's decoded, see
+// contestService.StatusEach. A count of 0 streams every submission starting
+// from from.
+func (s *userService) StatusEach(handle string, from, count uint, fn func(ContestStatus) error) error {
+	return s.StatusEachCtx(context.Background(), handle, from, count, fn)
+}
+
+func (s *userService) StatusEachCtx(ctx context.Context, handle string, from, count uint, fn func(ContestStatus) error) error {
+	params := map[string]string{
+		"handle": handle,
+		"from":   fmt.Sprint(from),
+	}
+	if count > 0 {
+		params["count"] = fmt.Sprint(count)
+	}
+	resp, err := call(ctx, s.client, "user.status", params)
+	return streamResponse(resp, err, fn)
+}
+
+// returns the rated users, sorted by decreasing rating. activeOnly keeps the
+// users who took part in a rated contest during the last month, includeRetired
+// adds the ones who haven't been online for a long time, and a contestId other
+// than 0 keeps the participants of that contest. The list is large, see
+// RatedListEach to avoid holding all of it in memory.
+func (s *userService) RatedList(activeOnly, includeRetired bool, contestId uint) (*[]User, error) {
+	return s.RatedListCtx(context.Background(), activeOnly, includeRetired, contestId)
+}
+
+func (s *userService) RatedListCtx(ctx context.Context, activeOnly, includeRetired bool, contestId uint) (*[]User, error) {
+	resp, err := call(ctx, s.client, "user.ratedList", ratedListParams(activeOnly, includeRetired, contestId))
+	return serializeResponse[[]User](resp, err)
+}
+
+// calls fn for every user of RatedList as soon as it's decoded. Iteration
+// stops at the first error returned by fn, which is then returned.
+func (s *userService) RatedListEach(activeOnly, includeRetired bool, contestId uint, fn func(User) error) error {
+	return s.RatedListEachCtx(context.Background(), activeOnly, includeRetired, contestId, fn)
+}
+
+func (s *userService) RatedListEachCtx(ctx context.Context, activeOnly, includeRetired bool, contestId uint, fn func(User) error) error {
+	resp, err := call(ctx, s.client, "user.ratedList", ratedListParams(activeOnly, includeRetired, contestId))
+	return streamResponse(resp, err, fn)
+}
+
+func ratedListParams(activeOnly, includeRetired bool, contestId uint) map[string]string {
+	params := map[string]string{
+		"activeOnly":     fmt.Sprint(activeOnly),
+		"includeRetired": fmt.Sprint(includeRetired),
+	}
+	if contestId != 0 {
+		params["contestId"] = fmt.Sprint(contestId)
+	}
+	return params
+}
+
+// returns every blog entry written by handle, without their content. See
+// blogService.FullEntries to fetch it.
+func (s *userService) BlogEntries(handle string) (*[]BlogEntry, error) {
+	return s.BlogEntriesCtx(context.Background(), handle)
+}
+
+func (s *userService) BlogEntriesCtx(ctx context.Context, handle string) (*[]BlogEntry, error) {
+	params := map[string]string{"handle": handle}
+	resp, err := call(ctx, s.client, "user.blogEntries", params)
+	return serializeResponse[[]BlogEntry](resp, err)
+}
+
+// Requires authentication, anonymous clients get ErrAuthRequired
 func (s *userService) Friends(onlyOnline bool) (*[]string, error) {
+	return s.FriendsCtx(context.Background(), onlyOnline)
+}
+
+func (s *userService) FriendsCtx(ctx context.Context, onlyOnline bool) (*[]string, error) {
 	params := map[string]string{"onlyOnline": fmt.Sprint(onlyOnline)}
-	resp, err := s.client.Get("user.friends", params)
+	resp, err := call(ctx, s.client, "user.friends", params)
 	return serializeResponse[[]string](resp, err)
 }
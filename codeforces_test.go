@@ -1,11 +1,14 @@
 package codeforces
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -34,6 +37,7 @@ func TestEntryByIdValid(t *testing.T) {
 	assert.Equal(t, "Codeforces Maintenance", resp.Title)
 	assert.Equal(t, "en", resp.Locale)
 	assert.Equal(t, []string{"codeforces", "maintenance"}, resp.Tags)
+	assert.Contains(t, resp.Content, "unavailable for a few hours")
 }
 
 func TestEntryByIdInvalid(t *testing.T) {
@@ -328,7 +332,7 @@ func TestFriends(t *testing.T) {
 		assert.Nil(t, err)
 	}))
 	defer ts.Close()
-	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	c := newDefaultClientWrapper(ts.URL+"/", "key", "secret")
 	us := userService{c}
 	resp, err := us.Friends(false)
 	assert.Nil(t, err)
@@ -338,6 +342,7 @@ func TestFriends(t *testing.T) {
 
 func TestStandingsEmptyRows(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.False(t, r.URL.Query().Has("handles"))
 		w.WriteHeader(200)
 		b, err := os.ReadFile("testdata/contest/standings/emptyrows.json")
 		assert.Nil(t, err)
@@ -363,6 +368,45 @@ func TestStandingsEmptyRows(t *testing.T) {
 	assert.Equal(t, contest, resp.Contest)
 }
 
+func TestStandingsWithOptions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "566", q.Get("contestId"))
+		assert.Equal(t, "tourist;Petr", q.Get("handles"))
+		assert.Equal(t, "CONTESTANT,OUT_OF_COMPETITION", q.Get("participantTypes"))
+		assert.Equal(t, "3", q.Get("room"))
+		assert.Equal(t, "true", q.Get("asManager"))
+		assert.Equal(t, "true", q.Get("showUnofficial"))
+		assert.False(t, q.Has("from"))
+		assert.False(t, q.Has("count"))
+		w.WriteHeader(200)
+		b, err := os.ReadFile("testdata/contest/standings/emptyrows.json")
+		assert.Nil(t, err)
+		_, err = w.Write(b)
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "key", "secret")
+	cs := contestService{c}
+	resp, err := cs.StandingsWithOptions(566, StandingsOptions{
+		Handles:          []string{"tourist", "Petr"},
+		ShowUnofficial:   true,
+		ParticipantTypes: []string{ParticipantContestant, ParticipantOutOfCompetition},
+		Room:             3,
+		AsManager:        true,
+	})
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, 566, resp.Contest.ID)
+}
+
+func TestStandingsAsManagerRequiresAuth(t *testing.T) {
+	cs := contestService{newDefaultClientWrapper("", "", "")}
+	resp, err := cs.StandingsWithOptions(566, StandingsOptions{AsManager: true})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrAuthRequired))
+}
+
 func TestRecentActionsValid(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
@@ -388,6 +432,36 @@ func TestRecentActionsInvalidCount(t *testing.T) {
 	assert.Nil(t, resp)
 }
 
+func TestRecentStatusValid(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/problemset.recentStatus", r.URL.Path)
+		assert.Equal(t, "2", r.URL.Query().Get("count"))
+		assert.False(t, r.URL.Query().Has("problemsetName"))
+		w.WriteHeader(200)
+		b, err := os.ReadFile("testdata/problems/recentstatus/recentstatus.json")
+		assert.Nil(t, err)
+		_, err = w.Write(b)
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	ps := problemService{c}
+	resp, err := ps.RecentStatus(2, "")
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+	assert.Len(t, *resp, 2)
+	assert.Equal(t, (*resp)[0].Verdict, "WRONG_ANSWER")
+	assert.Equal(t, (*resp)[1].Problem.Name, "Watermelon")
+}
+
+func TestRecentStatusInvalidCount(t *testing.T) {
+	c := newDefaultClientWrapper("", "", "")
+	ps := problemService{c}
+	resp, err := ps.RecentStatus(1001, "acmsguru")
+	assert.NotNil(t, err)
+	assert.Nil(t, resp)
+}
+
 func TestRatingChange(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
@@ -441,3 +515,205 @@ func TestFailedRequest(t *testing.T) {
 	assert.Nil(t, resp)
 	assert.EqualError(t, err, "400:Something went wrong")
 }
+
+func TestStatusCtxDeadline(t *testing.T) {
+	unblock := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-unblock:
+		}
+	}))
+	defer ts.Close()
+	defer close(unblock)
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	cs := contestService{c}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	resp, err := cs.StatusCtx(ctx, 566, 1, 10)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestProblemsetCtxCancelled(t *testing.T) {
+	hit := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	ps := problemService{c}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	resp, err := ps.ProblemsetCtx(ctx, nil)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.False(t, hit)
+}
+
+func TestStatusEach(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.False(t, r.URL.Query().Has("count"))
+		w.WriteHeader(200)
+		_, err := w.Write([]byte(`{"status":"OK","result":[{"id":1,"contestId":566},{"id":2,"contestId":566},{"id":3,"contestId":566}]}`))
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	cs := contestService{c}
+	var ids []int
+	err := cs.StatusEach(566, 1, 0, func(s ContestStatus) error {
+		ids = append(ids, s.ID)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, ids)
+
+	stop := errors.New("stop")
+	ids = nil
+	err = cs.StatusEach(566, 1, 0, func(s ContestStatus) error {
+		ids = append(ids, s.ID)
+		if s.ID == 2 {
+			return stop
+		}
+		return nil
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, []int{1, 2}, ids)
+}
+
+func TestUserStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/user.status", r.URL.Path)
+		assert.Equal(t, "tourist", r.URL.Query().Get("handle"))
+		assert.Equal(t, "1", r.URL.Query().Get("from"))
+		assert.Equal(t, "2", r.URL.Query().Get("count"))
+		w.WriteHeader(200)
+		b, err := os.ReadFile("testdata/user/status/touriststatus.json")
+		assert.Nil(t, err)
+		_, err = w.Write(b)
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	us := userService{c}
+	resp, err := us.Status("tourist", 1, 2)
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+	assert.Len(t, *resp, 2)
+	assert.Equal(t, (*resp)[0].ID, 195123456)
+	assert.Equal(t, (*resp)[0].Problem.Name, "Count the Number of Pairs")
+	assert.Equal(t, (*resp)[0].Author.ParticipantType, "CONTESTANT")
+	assert.Equal(t, (*resp)[1].ContestID, 566)
+	assert.Equal(t, (*resp)[1].Verdict, "OK")
+}
+
+func TestUserStatusEach(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.False(t, r.URL.Query().Has("count"))
+		w.WriteHeader(200)
+		b, err := os.ReadFile("testdata/user/status/touriststatus.json")
+		assert.Nil(t, err)
+		_, err = w.Write(b)
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	us := userService{c}
+	var ids []int
+	err := us.StatusEach("tourist", 1, 0, func(s ContestStatus) error {
+		ids = append(ids, s.ID)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{195123456, 12291750}, ids)
+}
+
+func TestRatedList(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/user.ratedList", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("activeOnly"))
+		assert.Equal(t, "false", r.URL.Query().Get("includeRetired"))
+		assert.Equal(t, "1800", r.URL.Query().Get("contestId"))
+		w.WriteHeader(200)
+		b, err := os.ReadFile("testdata/user/ratedlist/ratedlist.json")
+		assert.Nil(t, err)
+		_, err = w.Write(b)
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	us := userService{c}
+	resp, err := us.RatedList(true, false, 1800)
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+	assert.Len(t, *resp, 3)
+	assert.Equal(t, (*resp)[0].Handle, "tourist")
+	assert.Equal(t, (*resp)[1].Organization, "MIT")
+	assert.Equal(t, (*resp)[2].Rating, 3624)
+}
+
+func TestRatedListEach(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.False(t, r.URL.Query().Has("contestId"))
+		w.WriteHeader(200)
+		b, err := os.ReadFile("testdata/user/ratedlist/ratedlist.json")
+		assert.Nil(t, err)
+		_, err = w.Write(b)
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	us := userService{c}
+	byCountry := map[string]int{}
+	err := us.RatedListEach(false, true, 0, func(u User) error {
+		byCountry[u.Country]++
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"Belarus": 1, "United States": 1, "China": 1}, byCountry)
+}
+
+func TestBlogEntries(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/user.blogEntries", r.URL.Path)
+		assert.Equal(t, "MikeMirzayanov", r.URL.Query().Get("handle"))
+		w.WriteHeader(200)
+		b, err := os.ReadFile("testdata/user/blogentries/blogentries.json")
+		assert.Nil(t, err)
+		_, err = w.Write(b)
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	us := userService{c}
+	resp, err := us.BlogEntries("MikeMirzayanov")
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+	assert.Len(t, *resp, 2)
+	assert.Equal(t, (*resp)[0].ID, 115892)
+	assert.Equal(t, (*resp)[0].Title, "Codeforces Round 867 (Div. 3)")
+	assert.Equal(t, (*resp)[1].Tags, []string{"codeforces", "maintenance"})
+	assert.Empty(t, (*resp)[0].Content)
+}
+
+func TestFullEntries(t *testing.T) {
+	var requested []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/blogEntry.view", r.URL.Path)
+		requested = append(requested, r.URL.Query().Get("blogEntryId"))
+		w.WriteHeader(200)
+		b, err := os.ReadFile("testdata/blog/entry/123.json")
+		assert.Nil(t, err)
+		_, err = w.Write(b)
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	bs := blogService{c}
+	resp, err := bs.FullEntries([]BlogEntry{{ID: 123}, {ID: 124}})
+	assert.Nil(t, err)
+	assert.Len(t, *resp, 2)
+	assert.Equal(t, []string{"123", "124"}, requested)
+	assert.NotEmpty(t, (*resp)[0].Content)
+}
@@ -0,0 +1,90 @@
+package codeforces
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// collapses identical concurrent calls into a single one, whose outcome is
+// shared by every caller. Calls are identical when their method and
+// parameters match, as for caching.
+type dedupTransport struct {
+	next  Transport
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+type inflightCall struct {
+	done chan struct{}
+	// callers waiting for the outcome, besides the one making the call
+	waiters int
+	resp    *http.Response
+	body    []byte
+	err     error
+}
+
+func newDedupTransport(next Transport) *dedupTransport {
+	return &dedupTransport{next: next, calls: map[string]*inflightCall{}}
+}
+
+func (t *dedupTransport) Get(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+	key := cacheKey(method, params)
+	t.mu.Lock()
+	if call, ok := t.calls[key]; ok {
+		call.waiters++
+		t.mu.Unlock()
+		return t.wait(ctx, call, method, params)
+	}
+	call := &inflightCall{done: make(chan struct{})}
+	t.calls[key] = call
+	t.mu.Unlock()
+
+	resp, err := t.next.Get(ctx, method, params)
+	t.mu.Lock()
+	delete(t.calls, key)
+	shared := call.waiters > 0
+	t.mu.Unlock()
+	if !shared {
+		// nobody else is interested, so the body can still be streamed
+		close(call.done)
+		return resp, err
+	}
+	if err == nil {
+		call.body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		call.resp = resp
+	}
+	call.err = err
+	close(call.done)
+	if err != nil {
+		return nil, err
+	}
+	return call.response(), nil
+}
+
+func (t *dedupTransport) wait(ctx context.Context, call *inflightCall, method string, params map[string]string) (*http.Response, error) {
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if call.err != nil {
+		// the caller that made the call gave up, which says nothing about this one
+		if (errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded)) && ctx.Err() == nil {
+			return t.next.Get(ctx, method, params)
+		}
+		return nil, call.err
+	}
+	return call.response(), nil
+}
+
+// every caller gets its own copy of the response, reading the shared body
+func (c *inflightCall) response() *http.Response {
+	resp := *c.resp
+	resp.Body = io.NopCloser(bytes.NewReader(c.body))
+	return &resp
+}
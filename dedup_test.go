@@ -0,0 +1,143 @@
+package codeforces
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blocks every call until release is closed, answering with body or err
+func blockingTransport(calls *int, release chan struct{}, body string, err error) Transport {
+	var mu sync.Mutex
+	return TransportFunc(func(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+		mu.Lock()
+		*calls++
+		mu.Unlock()
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return jsonResponse(body), nil
+	})
+}
+
+func waitForWaiters(t *testing.T, d *dedupTransport, n int) {
+	for i := 0; i < 1000; i++ {
+		d.mu.Lock()
+		waiting := 0
+		for _, call := range d.calls {
+			waiting += call.waiters
+		}
+		d.mu.Unlock()
+		if waiting == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d waiters", n)
+}
+
+func TestDedupSharesResult(t *testing.T) {
+	calls := 0
+	release := make(chan struct{})
+	d := newDedupTransport(blockingTransport(&calls, release, `{"status":"OK","result":[{"handle":"tourist"}]}`, nil))
+	us := userService{d}
+	var wg sync.WaitGroup
+	results := make([]*[]User, 5)
+	errs := make([]error, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = us.Info([]string{"tourist"})
+		}(i)
+	}
+	waitForWaiters(t, d, 4)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, 1, calls)
+	for i := range results {
+		assert.Nil(t, errs[i])
+		assert.Equal(t, "tourist", (*results[i])[0].Handle)
+	}
+}
+
+func TestDedupSharesError(t *testing.T) {
+	calls := 0
+	release := make(chan struct{})
+	apiErr := &APIError{StatusCode: 400, Method: "user.info", Comment: "handles: User with handle x not found"}
+	d := newDedupTransport(blockingTransport(&calls, release, "", apiErr))
+	us := userService{d}
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = us.Info([]string{"x"})
+		}(i)
+	}
+	waitForWaiters(t, d, 2)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, 1, calls)
+	for _, err := range errs {
+		assert.True(t, errors.Is(err, ErrHandleNotFound))
+	}
+}
+
+func TestDedupDifferentParams(t *testing.T) {
+	calls := 0
+	release := make(chan struct{})
+	close(release)
+	d := newDedupTransport(blockingTransport(&calls, release, `{"status":"OK","result":[]}`, nil))
+	us := userService{d}
+	_, err := us.Info([]string{"tourist"})
+	assert.Nil(t, err)
+	_, err = us.Info([]string{"benq"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestDedupCancelledLeader(t *testing.T) {
+	calls := 0
+	release := make(chan struct{})
+	d := newDedupTransport(blockingTransport(&calls, release, `{"status":"OK","result":[]}`, nil))
+	cs := contestService{d}
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error)
+	go func() {
+		_, err := cs.ListCtx(ctx, false)
+		leaderErr <- err
+	}()
+	for i := 0; i < 1000; i++ {
+		d.mu.Lock()
+		started := len(d.calls) == 1
+		d.mu.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	followerErr := make(chan error)
+	go func() {
+		_, err := cs.List(false)
+		followerErr <- err
+	}()
+	waitForWaiters(t, d, 1)
+	cancel()
+	assert.ErrorIs(t, <-leaderErr, context.Canceled)
+	// the follower makes its own call instead of failing
+	close(release)
+	assert.Nil(t, <-followerErr)
+	assert.Equal(t, 2, calls)
+}
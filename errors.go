@@ -0,0 +1,94 @@
+package codeforces
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Categories of api failures, parsed from the comment sent by codeforces.
+// They can be matched against any error returned by the client with errors.Is.
+var (
+	ErrHandleNotFound    = errors.New("handle not found")
+	ErrContestNotFound   = errors.New("contest not found")
+	ErrCallLimitExceeded = errors.New("call limit exceeded")
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrContestNotStarted = errors.New("contest has not started")
+)
+
+// returned without contacting the api when a client without key and secret
+// calls a method that requires authentication
+var ErrAuthRequired = errors.New("authentication required")
+
+// returned when a response body exceeds the size set with WithMaxResponseSize
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// APIError is returned when codeforces answers a call with a failure.
+type APIError struct {
+	StatusCode int
+	// api method that failed, e.g. "user.info"
+	Method string
+	// raw comment explaining the failure, e.g. "handles: User with handle x not found"
+	Comment string
+	// parameters of the call, without apiKey, time and apiSig
+	Params map[string]string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprint(e.StatusCode) + ":" + e.Comment
+}
+
+// returns the category the failure belongs to, or nil if it's not a known one
+func (e *APIError) Unwrap() error {
+	comment := strings.ToLower(e.Comment)
+	switch {
+	case strings.Contains(comment, "call limit exceeded"):
+		return ErrCallLimitExceeded
+	case strings.Contains(comment, "user with handle") && strings.Contains(comment, "not found"):
+		return ErrHandleNotFound
+	case strings.Contains(comment, "contest with id") && strings.Contains(comment, "not found"):
+		return ErrContestNotFound
+	case strings.Contains(comment, "has not started"):
+		return ErrContestNotStarted
+	case e.StatusCode == 401 || e.StatusCode == 403,
+		strings.Contains(comment, "unauthorized"),
+		strings.Contains(comment, "incorrect api key"),
+		strings.Contains(comment, "incorrect signature"):
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// MalformedResponseError is returned when a response isn't a valid api envelope,
+// e.g. the html page served while codeforces is under maintenance.
+type MalformedResponseError struct {
+	StatusCode int
+	Method     string
+	// beginning of the response body
+	Snippet string
+	// decoding error, if any
+	Err error
+}
+
+func (e *MalformedResponseError) Error() string {
+	msg := fmt.Sprintf("%d:malformed response from %s: %q", e.StatusCode, e.Method, e.Snippet)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *MalformedResponseError) Unwrap() error {
+	return e.Err
+}
+
+// removes the authentication parameters, which must not end up in errors or logs
+func publicParams(params map[string]string) map[string]string {
+	public := make(map[string]string, len(params))
+	for k, v := range params {
+		if k != "apiKey" && k != "apiSig" && k != "time" {
+			public[k] = v
+		}
+	}
+	return public
+}
@@ -0,0 +1,111 @@
+package codeforces
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIErrorCategories(t *testing.T) {
+	cases := []struct {
+		err      APIError
+		category error
+	}{
+		{err: APIError{StatusCode: 400, Comment: "handles: User with handle abcdefghijk not found"}, category: ErrHandleNotFound},
+		{err: APIError{StatusCode: 400, Comment: "handle: User with handle x not found"}, category: ErrHandleNotFound},
+		{err: APIError{StatusCode: 400, Comment: "contestId: Contest with id 99999 not found"}, category: ErrContestNotFound},
+		{err: APIError{StatusCode: 503, Comment: "Call limit exceeded"}, category: ErrCallLimitExceeded},
+		{err: APIError{StatusCode: 400, Comment: "apiKey: Incorrect API key"}, category: ErrUnauthorized},
+		{err: APIError{StatusCode: 400, Comment: "Incorrect signature"}, category: ErrUnauthorized},
+		{err: APIError{StatusCode: 400, Comment: "contestId: Contest with id 1800 has not started"}, category: ErrContestNotStarted},
+		{err: APIError{StatusCode: 400, Comment: "Something went wrong"}, category: nil},
+	}
+	for _, tt := range cases {
+		assert.Equal(t, tt.category, tt.err.Unwrap(), tt.err.Comment)
+		if tt.category != nil {
+			assert.True(t, errors.Is(&tt.err, tt.category))
+		}
+	}
+}
+
+func TestAPIErrorFromResponse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		_, err := w.Write([]byte(`{"status":"FAILED","comment":"handles: User with handle nobody not found"}`))
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "key", "secret")
+	us := userService{c}
+	resp, err := us.Info([]string{"nobody"})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrHandleNotFound))
+	assert.False(t, errors.Is(err, ErrContestNotFound))
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 400, apiErr.StatusCode)
+	assert.Equal(t, "user.info", apiErr.Method)
+	assert.Equal(t, "handles: User with handle nobody not found", apiErr.Comment)
+	assert.Equal(t, map[string]string{"handles": "nobody"}, apiErr.Params)
+}
+
+func TestFailedEnvelopeWithStatusOK(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		_, err := w.Write([]byte(`{"status":"FAILED","comment":"contestId: Contest with id 99999 not found"}`))
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	cs := contestService{c}
+	resp, err := cs.Standings(99999, 1, 1, nil, false)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrContestNotFound))
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 200, apiErr.StatusCode)
+	assert.Equal(t, "contest.standings", apiErr.Method)
+}
+
+func TestNonJSONBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		_, err := w.Write([]byte("<html><body>Codeforces is temporarily unavailable</body></html>"))
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	cs := contestService{c}
+	resp, err := cs.List(false)
+	assert.Nil(t, resp)
+	var malformed *MalformedResponseError
+	assert.True(t, errors.As(err, &malformed))
+	assert.Equal(t, "contest.list", malformed.Method)
+	assert.Contains(t, malformed.Snippet, "temporarily unavailable")
+}
+
+func TestFailedEnvelopeFromCustomTransport(t *testing.T) {
+	mock := TransportFunc(func(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+		return jsonResponse(`{"result":null,"status":"FAILED","comment":"Call limit exceeded"}`), nil
+	})
+	c := NewCustomClient(mock)
+	resp, err := c.Problems.Problemset(nil)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrCallLimitExceeded))
+}
+
+func TestTruncatedJSONBody(t *testing.T) {
+	mock := TransportFunc(func(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+		return jsonResponse(`{"status":"OK","result":[{"id":1`), nil
+	})
+	c := NewCustomClient(mock)
+	resp, err := c.Contest.List(false)
+	assert.Nil(t, resp)
+	var malformed *MalformedResponseError
+	assert.True(t, errors.As(err, &malformed))
+	assert.Equal(t, `{"status":"OK","result":[{"id":1`, malformed.Snippet)
+}
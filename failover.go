@@ -0,0 +1,89 @@
+package codeforces
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// for how long a base url that failed is skipped, in favour of the next ones
+const DefaultFailoverCooldown = time.Minute
+
+// ordered list of base urls serving the same api, remembering which ones
+// failed recently
+type endpoints struct {
+	urls []string
+	mu   sync.Mutex
+	// when each failed url becomes eligible again
+	downUntil map[string]time.Time
+}
+
+func newEndpoints(urls ...string) *endpoints {
+	normalized := make([]string, len(urls))
+	for i, u := range urls {
+		if !strings.HasSuffix(u, "/") {
+			u += "/"
+		}
+		normalized[i] = u
+	}
+	return &endpoints{urls: normalized, downUntil: map[string]time.Time{}}
+}
+
+// the urls to try for a call: the healthy ones in order, followed by the ones
+// that failed recently, as a last resort
+func (e *endpoints) order() []string {
+	if len(e.urls) == 1 {
+		return e.urls
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := time.Now()
+	healthy := make([]string, 0, len(e.urls))
+	var down []string
+	for _, u := range e.urls {
+		if now.Before(e.downUntil[u]) {
+			down = append(down, u)
+		} else {
+			healthy = append(healthy, u)
+		}
+	}
+	return append(healthy, down...)
+}
+
+func (e *endpoints) markDown(u string, cooldown time.Duration) {
+	if len(e.urls) == 1 {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.downUntil[u] = time.Now().Add(cooldown)
+}
+
+func (e *endpoints) markUp(u string) {
+	if len(e.urls) == 1 {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.downUntil, u)
+}
+
+// reports whether a call that failed with err might succeed on another base
+// url: the host couldn't be reached or answered with a server error. The call
+// limit is tied to the api key, so other hosts wouldn't help.
+func shouldFailover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrCallLimitExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	var malformed *MalformedResponseError
+	if errors.As(err, &malformed) {
+		return malformed.StatusCode >= 500
+	}
+	return !errors.Is(err, ErrResponseTooLarge)
+}
@@ -0,0 +1,120 @@
+package codeforces
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// answers every call with status and body, counting them
+func countingServer(calls *int, status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestFailover(t *testing.T) {
+	primaryCalls, mirrorCalls := 0, 0
+	primary := countingServer(&primaryCalls, 502, "<html>Bad Gateway</html>")
+	defer primary.Close()
+	mirror := countingServer(&mirrorCalls, 200, `{"status":"OK","result":[]}`)
+	defer mirror.Close()
+	var hosts []string
+	hook := HookMiddleware(func(ctx context.Context, info CallInfo) {
+		hosts = append(hosts, info.Host)
+	})
+	c := NewClient("", "",
+		WithBaseURLs(primary.URL, mirror.URL),
+		WithFailoverCooldown(50*time.Millisecond),
+		WithRateLimiter(nil),
+		WithRetryPolicy(nil),
+		WithMiddleware(hook),
+	)
+	mirrorURL, _ := url.Parse(mirror.URL)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Contest.List(false)
+		assert.Nil(t, err)
+	}
+	// the primary is skipped while cooling down
+	assert.Equal(t, 1, primaryCalls)
+	assert.Equal(t, 2, mirrorCalls)
+	assert.Equal(t, []string{mirrorURL.Host, mirrorURL.Host}, hosts)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err := c.Contest.List(false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, primaryCalls)
+	assert.Equal(t, 3, mirrorCalls)
+}
+
+func TestFailoverConnectionError(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	calls := 0
+	mirror := countingServer(&calls, 200, `{"status":"OK","result":[]}`)
+	defer mirror.Close()
+	tr := NewHTTPTransport(nil, down.URL, mirror.URL)
+	resp, err := tr.Get(context.Background(), "contest.list", nil)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 1, calls)
+}
+
+func TestNoFailover(t *testing.T) {
+	for _, tc := range []struct {
+		status int
+		body   string
+	}{
+		{400, `{"status":"FAILED","comment":"handles: User with handle x not found"}`},
+		{503, `{"status":"FAILED","comment":"Call limit exceeded"}`},
+	} {
+		primaryCalls, mirrorCalls := 0, 0
+		primary := countingServer(&primaryCalls, tc.status, tc.body)
+		mirror := countingServer(&mirrorCalls, 200, `{"status":"OK","result":[]}`)
+		tr := NewHTTPTransport(nil, primary.URL, mirror.URL)
+		_, err := tr.Get(context.Background(), "user.info", map[string]string{"handles": "x"})
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, 1, primaryCalls)
+		assert.Equal(t, 0, mirrorCalls)
+		primary.Close()
+		mirror.Close()
+	}
+}
+
+func TestFailoverAllDown(t *testing.T) {
+	calls := 0
+	a := countingServer(&calls, 500, "")
+	defer a.Close()
+	b := countingServer(&calls, 503, "")
+	defer b.Close()
+	tr := NewHTTPTransport(nil, a.URL, b.URL)
+	_, err := tr.Get(context.Background(), "contest.list", nil)
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 503, apiErr.StatusCode)
+	// both are cooling down, but still tried as a last resort
+	_, err = tr.Get(context.Background(), "contest.list", nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, 4, calls)
+}
+
+func TestEmptyBaseURLs(t *testing.T) {
+	c := newDefaultClientWrapper(defaultbaseURLString, "", "")
+	WithBaseURLs()(c)
+	assert.Equal(t, []string{defaultbaseURLString}, c.http.endpoints.urls)
+
+	tr := &httpTransport{endpoints: newEndpoints(), client: http.DefaultClient}
+	resp, err := tr.Get(context.Background(), "contest.list", nil)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, errNoBaseURL))
+}
@@ -22,7 +22,7 @@ type IntegrationSuite struct {
 func (suite *IntegrationSuite) SetupTest() {
 	apiKey := os.Getenv("CF_API_KEY")
 	apiSecret := os.Getenv("CF_API_SECRET")
-	suite.c = *NewClient(apiKey, apiSecret)
+	suite.c = *NewClient(apiKey, apiSecret, WithRateLimiter(SharedRateLimiter()))
 }
 
 func TestIntegration(t *testing.T) {
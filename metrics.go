@@ -0,0 +1,253 @@
+package codeforces
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// upper bounds of the latency histogram buckets, calls slower than the last one
+// fall in an additional unbounded bucket
+var DefaultLatencyBuckets = []time.Duration{
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+}
+
+// Metrics aggregates the calls going through its middleware, per api method,
+// along with the time spent waiting for the rate limiter when installed with
+// WithMetrics. It's safe for concurrent use, and can be shared by several
+// clients.
+//
+// Metrics implements expvar.Var, so it can be published with expvar.Publish,
+// and http.Handler, serving the Prometheus text format.
+type Metrics struct {
+	buckets []time.Duration
+
+	mu      sync.Mutex
+	methods map[string]*MethodMetrics
+	waits   uint64
+	waited  time.Duration
+}
+
+// MethodMetrics holds the totals of a single api method.
+type MethodMetrics struct {
+	Calls  uint64
+	Errors uint64
+	// failed calls by category, see ErrorCategory
+	ErrorsByCategory map[string]uint64
+	// sum of the latencies of every call
+	Latency time.Duration
+	// LatencyCounts[i] counts the calls that took more than the previous bucket
+	// bound and at most the i-th one. The last element counts the calls slower
+	// than every bound.
+	LatencyCounts []uint64
+}
+
+// uses DefaultLatencyBuckets
+func NewMetrics() *Metrics {
+	return NewMetricsWithBuckets(DefaultLatencyBuckets)
+}
+
+// buckets must be sorted in increasing order
+func NewMetricsWithBuckets(buckets []time.Duration) *Metrics {
+	return &Metrics{buckets: buckets, methods: map[string]*MethodMetrics{}}
+}
+
+// returns a middleware recording every call into m. Use WithMetrics to also
+// record the rate limiter wait time.
+func (m *Metrics) Middleware() Middleware {
+	return HookMiddleware(func(ctx context.Context, info CallInfo) {
+		m.observe(info)
+	})
+}
+
+func (m *Metrics) observe(info CallInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mm, ok := m.methods[info.Method]
+	if !ok {
+		mm = &MethodMetrics{ErrorsByCategory: map[string]uint64{}, LatencyCounts: make([]uint64, len(m.buckets)+1)}
+		m.methods[info.Method] = mm
+	}
+	mm.Calls++
+	mm.Latency += info.Latency
+	mm.LatencyCounts[sort.Search(len(m.buckets), func(i int) bool { return info.Latency <= m.buckets[i] })]++
+	if info.Err != nil {
+		mm.Errors++
+		mm.ErrorsByCategory[ErrorCategory(info.Err)]++
+	}
+}
+
+// nil-safe, so that clients without metrics don't have to check
+func (m *Metrics) observeWait(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.waits++
+	m.waited += d
+}
+
+// returns a copy of the current totals, keyed by method
+func (m *Metrics) Snapshot() map[string]MethodMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]MethodMetrics, len(m.methods))
+	for method, mm := range m.methods {
+		c := *mm
+		c.ErrorsByCategory = make(map[string]uint64, len(mm.ErrorsByCategory))
+		for k, v := range mm.ErrorsByCategory {
+			c.ErrorsByCategory[k] = v
+		}
+		c.LatencyCounts = append([]uint64(nil), mm.LatencyCounts...)
+		out[method] = c
+	}
+	return out
+}
+
+// returns how many times calls waited for the rate limiter, and for how long
+// in total
+func (m *Metrics) LimiterWait() (waits uint64, total time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.waits, m.waited
+}
+
+var errorCategories = []struct {
+	err  error
+	name string
+}{
+	{ErrHandleNotFound, "handle_not_found"},
+	{ErrContestNotFound, "contest_not_found"},
+	{ErrCallLimitExceeded, "call_limit_exceeded"},
+	{ErrUnauthorized, "unauthorized"},
+	{ErrContestNotStarted, "contest_not_started"},
+	{ErrAuthRequired, "auth_required"},
+	{ErrResponseTooLarge, "response_too_large"},
+	{context.DeadlineExceeded, "timeout"},
+	{context.Canceled, "canceled"},
+}
+
+// returns a short name for the kind of failure err is, e.g. "handle_not_found",
+// "api" for other api failures, "malformed", "network" or "other"
+func ErrorCategory(err error) string {
+	for _, c := range errorCategories {
+		if errors.Is(err, c.err) {
+			return c.name
+		}
+	}
+	var malformed *MalformedResponseError
+	var apiErr *APIError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &malformed):
+		return "malformed"
+	case errors.As(err, &apiErr):
+		return "api"
+	case errors.As(err, &netErr):
+		return "network"
+	}
+	return "other"
+}
+
+// the expvar representation, durations are in seconds
+func (m *Metrics) String() string {
+	type method struct {
+		Calls            uint64            `json:"calls"`
+		Errors           uint64            `json:"errors"`
+		ErrorsByCategory map[string]uint64 `json:"errorsByCategory"`
+		LatencySeconds   float64           `json:"latencySeconds"`
+		LatencyCounts    []uint64          `json:"latencyCounts"`
+	}
+	var out struct {
+		Methods              map[string]method `json:"methods"`
+		LatencyBucketSeconds []float64         `json:"latencyBucketSeconds"`
+		LimiterWaits         uint64            `json:"limiterWaits"`
+		LimiterWaitSeconds   float64           `json:"limiterWaitSeconds"`
+	}
+	out.Methods = map[string]method{}
+	for name, mm := range m.Snapshot() {
+		out.Methods[name] = method{mm.Calls, mm.Errors, mm.ErrorsByCategory, mm.Latency.Seconds(), mm.LatencyCounts}
+	}
+	for _, b := range m.buckets {
+		out.LatencyBucketSeconds = append(out.LatencyBucketSeconds, b.Seconds())
+	}
+	waits, waited := m.LimiterWait()
+	out.LimiterWaits, out.LimiterWaitSeconds = waits, waited.Seconds()
+	b, _ := json.Marshal(out)
+	return string(b)
+}
+
+// serves the metrics in the Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = w.Write([]byte(m.prometheus()))
+}
+
+func (m *Metrics) prometheus() string {
+	snapshot := m.Snapshot()
+	methods := make([]string, 0, len(snapshot))
+	for method := range snapshot {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	seconds := func(d time.Duration) string {
+		return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+	}
+
+	var b strings.Builder
+	b.WriteString("# HELP codeforces_api_calls_total Calls made to the Codeforces api.\n")
+	b.WriteString("# TYPE codeforces_api_calls_total counter\n")
+	for _, method := range methods {
+		fmt.Fprintf(&b, "codeforces_api_calls_total{method=%q} %d\n", method, snapshot[method].Calls)
+	}
+	b.WriteString("# HELP codeforces_api_errors_total Failed calls to the Codeforces api, by category.\n")
+	b.WriteString("# TYPE codeforces_api_errors_total counter\n")
+	for _, method := range methods {
+		categories := make([]string, 0, len(snapshot[method].ErrorsByCategory))
+		for c := range snapshot[method].ErrorsByCategory {
+			categories = append(categories, c)
+		}
+		sort.Strings(categories)
+		for _, c := range categories {
+			fmt.Fprintf(&b, "codeforces_api_errors_total{method=%q,category=%q} %d\n", method, c, snapshot[method].ErrorsByCategory[c])
+		}
+	}
+	b.WriteString("# HELP codeforces_api_call_duration_seconds Latency of the calls to the Codeforces api.\n")
+	b.WriteString("# TYPE codeforces_api_call_duration_seconds histogram\n")
+	for _, method := range methods {
+		mm := snapshot[method]
+		var cumulative uint64
+		for i, bound := range m.buckets {
+			cumulative += mm.LatencyCounts[i]
+			fmt.Fprintf(&b, "codeforces_api_call_duration_seconds_bucket{method=%q,le=%q} %d\n", method, seconds(bound), cumulative)
+		}
+		fmt.Fprintf(&b, "codeforces_api_call_duration_seconds_bucket{method=%q,le=\"+Inf\"} %d\n", method, mm.Calls)
+		fmt.Fprintf(&b, "codeforces_api_call_duration_seconds_sum{method=%q} %s\n", method, seconds(mm.Latency))
+		fmt.Fprintf(&b, "codeforces_api_call_duration_seconds_count{method=%q} %d\n", method, mm.Calls)
+	}
+	waits, waited := m.LimiterWait()
+	b.WriteString("# HELP codeforces_rate_limiter_waits_total Calls that went through the rate limiter.\n")
+	b.WriteString("# TYPE codeforces_rate_limiter_waits_total counter\n")
+	fmt.Fprintf(&b, "codeforces_rate_limiter_waits_total %d\n", waits)
+	b.WriteString("# HELP codeforces_rate_limiter_wait_seconds_total Time spent waiting for the rate limiter.\n")
+	b.WriteString("# TYPE codeforces_rate_limiter_wait_seconds_total counter\n")
+	fmt.Fprintf(&b, "codeforces_rate_limiter_wait_seconds_total %s\n", seconds(waited))
+	return b.String()
+}
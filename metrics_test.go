@@ -0,0 +1,87 @@
+package codeforces
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	m := NewMetrics()
+	calls := 0
+	ok := Chain(countingTransport(&calls, `{"status":"OK","result":[]}`), m.Middleware())
+	failing := Chain(TransportFunc(func(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+		return nil, &APIError{StatusCode: 503, Method: method, Comment: "Call limit exceeded"}
+	}), m.Middleware())
+	for i := 0; i < 3; i++ {
+		resp, err := ok.Get(context.Background(), "contest.list", nil)
+		assert.Nil(t, err)
+		resp.Body.Close()
+	}
+	_, _ = failing.Get(context.Background(), "user.info", nil)
+	snapshot := m.Snapshot()
+	assert.Equal(t, uint64(3), snapshot["contest.list"].Calls)
+	assert.Equal(t, uint64(0), snapshot["contest.list"].Errors)
+	assert.Equal(t, uint64(3), snapshot["contest.list"].LatencyCounts[0])
+	assert.Equal(t, uint64(1), snapshot["user.info"].Calls)
+	assert.Equal(t, uint64(1), snapshot["user.info"].Errors)
+	assert.Equal(t, map[string]uint64{"call_limit_exceeded": 1}, snapshot["user.info"].ErrorsByCategory)
+}
+
+func TestMetricsHistogram(t *testing.T) {
+	m := NewMetricsWithBuckets([]time.Duration{time.Second, time.Minute})
+	for _, d := range []time.Duration{time.Millisecond, time.Second, 2 * time.Second, time.Hour} {
+		m.observe(CallInfo{Method: "contest.list", Latency: d})
+	}
+	assert.Equal(t, []uint64{2, 1, 1}, m.Snapshot()["contest.list"].LatencyCounts)
+	out := m.prometheus()
+	assert.True(t, strings.Contains(out, `codeforces_api_call_duration_seconds_bucket{method="contest.list",le="1"} 2`), out)
+	assert.True(t, strings.Contains(out, `codeforces_api_call_duration_seconds_bucket{method="contest.list",le="60"} 3`), out)
+	assert.True(t, strings.Contains(out, `codeforces_api_call_duration_seconds_bucket{method="contest.list",le="+Inf"} 4`), out)
+	assert.True(t, strings.Contains(out, `codeforces_api_call_duration_seconds_count{method="contest.list"} 4`), out)
+}
+
+func TestErrorCategory(t *testing.T) {
+	assert.Equal(t, "handle_not_found", ErrorCategory(&APIError{StatusCode: 400, Comment: "handles: User with handle x not found"}))
+	assert.Equal(t, "api", ErrorCategory(&APIError{StatusCode: 400, Comment: "count: Field should be positive"}))
+	assert.Equal(t, "malformed", ErrorCategory(&MalformedResponseError{StatusCode: 502}))
+	assert.Equal(t, "timeout", ErrorCategory(context.DeadlineExceeded))
+	assert.Equal(t, "auth_required", ErrorCategory(ErrAuthRequired))
+	assert.Equal(t, "other", ErrorCategory(errors.New("boom")))
+}
+
+func TestWithMetrics(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"OK","result":[]}`))
+	}))
+	defer ts.Close()
+	m := NewMetrics()
+	c := NewClient("", "", WithBaseURL(ts.URL), WithRateLimiter(NewRateLimiter(20*time.Millisecond)), WithMetrics(m))
+	for i := 0; i < 3; i++ {
+		_, err := c.Contest.List(false)
+		assert.Nil(t, err)
+	}
+	waits, waited := m.LimiterWait()
+	assert.Equal(t, uint64(3), waits)
+	assert.GreaterOrEqual(t, waited, 30*time.Millisecond)
+	assert.Equal(t, uint64(3), m.Snapshot()["contest.list"].Calls)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain"))
+	assert.True(t, strings.Contains(rec.Body.String(), `codeforces_api_calls_total{method="contest.list"} 3`))
+	assert.True(t, strings.Contains(rec.Body.String(), "codeforces_rate_limiter_waits_total 3"))
+
+	var _ expvar.Var = m
+	var exported map[string]any
+	assert.Nil(t, json.Unmarshal([]byte(m.String()), &exported))
+	assert.Equal(t, float64(3), exported["limiterWaits"])
+}
@@ -0,0 +1,190 @@
+package codeforces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Middleware wraps a Transport to observe or alter the calls going through it.
+type Middleware func(next Transport) Transport
+
+// wraps t with mws, the first one being the outermost
+func Chain(t Transport, mws ...Middleware) Transport {
+	for i := len(mws) - 1; i >= 0; i-- {
+		t = mws[i](t)
+	}
+	return t
+}
+
+// CallInfo describes a finished call, as passed to hooks.
+type CallInfo struct {
+	Method string
+	// parameters of the call, without apiKey, time and apiSig
+	Params map[string]string
+	// set by RequestIDMiddleware, empty otherwise
+	RequestID string
+	Latency   time.Duration
+	// status of the http response, 0 when none was received
+	StatusCode int
+	// host that served the response, e.g. "codeforces.com". Empty when the
+	// call failed or was answered without contacting the api, e.g. by a cache.
+	Host string
+	Err  error
+}
+
+// returns a middleware calling hook after every call. Calls that return a
+// response are only reported once its body has been decoded, or closed, so
+// that the latency covers the whole body and decoding errors are reported.
+func HookMiddleware(hook func(ctx context.Context, info CallInfo)) Middleware {
+	return func(next Transport) Transport {
+		return TransportFunc(func(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.Get(ctx, method, params)
+			info := CallInfo{
+				Method:     method,
+				Params:     publicParams(params),
+				RequestID:  RequestIDFromContext(ctx),
+				StatusCode: statusCode(resp, err),
+				Host:       host(resp),
+				Err:        err,
+			}
+			if err != nil {
+				info.Latency = time.Since(start)
+				hook(ctx, info)
+				return nil, err
+			}
+			resp.Body = &hookBody{rc: resp.Body, finish: func(err error) {
+				info.Latency = time.Since(start)
+				info.Err = err
+				if err != nil {
+					info.StatusCode = statusCode(nil, err)
+				}
+				hook(ctx, info)
+			}}
+			return resp, nil
+		})
+	}
+}
+
+// calls finish once the body has been decoded or closed, with the error
+// decoding or reading it failed with
+type hookBody struct {
+	rc      io.ReadCloser
+	readErr error
+	once    sync.Once
+	finish  func(err error)
+}
+
+func (b *hookBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	if err != nil && err != io.EOF {
+		b.readErr = err
+	}
+	return n, err
+}
+
+func (b *hookBody) reportDecode(err error) {
+	// bodies wrapped by several hooks report to every one of them
+	if r, ok := b.rc.(decodeReporter); ok {
+		r.reportDecode(err)
+	}
+	b.once.Do(func() { b.finish(err) })
+}
+
+func (b *hookBody) Close() error {
+	err := b.rc.Close()
+	b.once.Do(func() { b.finish(b.readErr) })
+	return err
+}
+
+func host(resp *http.Response) string {
+	if resp == nil || resp.Request == nil || resp.Request.URL == nil {
+		return ""
+	}
+	return resp.Request.URL.Host
+}
+
+func statusCode(resp *http.Response, err error) int {
+	var apiErr *APIError
+	var malformed *MalformedResponseError
+	switch {
+	case err == nil:
+		return resp.StatusCode
+	case errors.As(err, &apiErr):
+		return apiErr.StatusCode
+	case errors.As(err, &malformed):
+		return malformed.StatusCode
+	}
+	return 0
+}
+
+// logs every call to logger, at debug level when it succeeds and at warn level
+// when it fails
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return HookMiddleware(func(ctx context.Context, info CallInfo) {
+		level := slog.LevelDebug
+		if info.Err != nil {
+			level = slog.LevelWarn
+		}
+		attrs := []slog.Attr{
+			slog.String("method", info.Method),
+			slog.Any("params", info.Params),
+			slog.Duration("latency", info.Latency),
+			slog.Int("status", info.StatusCode),
+		}
+		if info.Host != "" {
+			attrs = append(attrs, slog.String("host", info.Host))
+		}
+		if info.RequestID != "" {
+			attrs = append(attrs, slog.String("request_id", info.RequestID))
+		}
+		if info.Err != nil {
+			attrs = append(attrs, slog.Any("error", info.Err))
+		}
+		logger.LogAttrs(ctx, level, "codeforces api call", attrs...)
+	})
+}
+
+type requestIDKey struct{}
+
+// attaches a request id to ctx, which RequestIDMiddleware keeps instead of
+// generating a new one
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// header carrying the request id of a call, so that it can be correlated with
+// proxy or server logs
+const RequestIDHeader = "X-Request-Id"
+
+// tags every call with a request id, generated by newID or at random when nil.
+// The id is available to the inner middlewares and hooks through the context,
+// and sent in the RequestIDHeader of every http request made for the call,
+// retries included.
+func RequestIDMiddleware(newID func() string) Middleware {
+	if newID == nil {
+		newID = func() string {
+			return fmt.Sprintf("%016x", rand.Uint64())
+		}
+	}
+	return func(next Transport) Transport {
+		return TransportFunc(func(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+			if RequestIDFromContext(ctx) == "" {
+				ctx = ContextWithRequestID(ctx, newID())
+			}
+			return next.Get(ctx, method, params)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package codeforces
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next Transport) Transport {
+			return TransportFunc(func(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+				order = append(order, name)
+				return next.Get(ctx, method, params)
+			})
+		}
+	}
+	calls := 0
+	tr := Chain(countingTransport(&calls, `{"status":"OK","result":[]}`), tag("outer"), tag("inner"))
+	_, err := tr.Get(context.Background(), "contest.list", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"outer", "inner"}, order)
+	assert.Equal(t, 1, calls)
+}
+
+func TestHookMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		_, _ = w.Write([]byte(`{"status":"FAILED","comment":"handles: User with handle x not found"}`))
+	}))
+	defer ts.Close()
+	var infos []CallInfo
+	hook := HookMiddleware(func(ctx context.Context, info CallInfo) {
+		infos = append(infos, info)
+	})
+	c := NewClient("key", "secret", WithBaseURL(ts.URL), WithRateLimiter(nil), WithRetryPolicy(nil), WithMiddleware(hook))
+	_, err := c.User.Info([]string{"x"})
+	assert.True(t, errors.Is(err, ErrHandleNotFound))
+	assert.Len(t, infos, 1)
+	assert.Equal(t, "user.info", infos[0].Method)
+	assert.Equal(t, map[string]string{"handles": "x"}, infos[0].Params)
+	assert.Equal(t, 400, infos[0].StatusCode)
+	assert.True(t, errors.Is(infos[0].Err, ErrHandleNotFound))
+	assert.Greater(t, infos[0].Latency, time.Duration(0))
+}
+
+func TestHookDecodeErrors(t *testing.T) {
+	for name, body := range map[string]string{
+		"truncated":           `{"status":"OK","result":[{"handle":"tou`,
+		"status not first":    `{"comment":"handles: User with handle x not found","status":"FAILED"}`,
+		"failed after result": `{"result":[],"status":"FAILED","comment":"Internal Server Error"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var infos []CallInfo
+			m := NewMetrics()
+			hook := HookMiddleware(func(ctx context.Context, info CallInfo) {
+				infos = append(infos, info)
+			})
+			calls := 0
+			c := NewClient("", "", WithTransport(countingTransport(&calls, body)), WithRateLimiter(nil), WithRetryPolicy(nil),
+				WithMiddleware(hook, m.Middleware()))
+			_, err := c.User.Info([]string{"x"})
+			assert.NotNil(t, err)
+			assert.Len(t, infos, 1)
+			assert.Equal(t, err, infos[0].Err)
+			assert.Equal(t, uint64(1), m.Snapshot()["user.info"].Errors)
+		})
+	}
+}
+
+func TestHookRedactsParams(t *testing.T) {
+	var info CallInfo
+	hook := HookMiddleware(func(ctx context.Context, i CallInfo) { info = i })
+	calls := 0
+	signed := &SigningTransport{Next: countingTransport(&calls, `{"status":"OK","result":[]}`), Signer: Signer{Key: "key", Secret: "secret"}}
+	tr := Chain(signed, hook)
+	resp, err := tr.Get(context.Background(), "user.friends", map[string]string{"onlyOnline": "false"})
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, map[string]string{"onlyOnline": "false"}, info.Params)
+	assert.Equal(t, 200, info.StatusCode)
+
+	// below the signing layer the authentication parameters are dropped
+	tr = &SigningTransport{Next: Chain(countingTransport(&calls, `{"status":"OK","result":[]}`), hook), Signer: Signer{Key: "key", Secret: "secret"}}
+	resp, err = tr.Get(context.Background(), "user.friends", map[string]string{"onlyOnline": "false"})
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, map[string]string{"onlyOnline": "false"}, info.Params)
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	calls := 0
+	tr := Chain(countingTransport(&calls, `{"status":"OK","result":[]}`), RequestIDMiddleware(func() string { return "abc" }), LoggingMiddleware(logger))
+	resp, err := tr.Get(context.Background(), "contest.list", map[string]string{"gym": "false"})
+	assert.Nil(t, err)
+	resp.Body.Close()
+	out := buf.String()
+	assert.True(t, strings.Contains(out, "level=DEBUG"), out)
+	assert.True(t, strings.Contains(out, "method=contest.list"), out)
+	assert.True(t, strings.Contains(out, "request_id=abc"), out)
+	assert.True(t, strings.Contains(out, "status=200"), out)
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var ids []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get(RequestIDHeader))
+		_, _ = w.Write([]byte(`{"status":"OK","result":[]}`))
+	}))
+	defer ts.Close()
+	c := NewClient("", "", WithBaseURL(ts.URL), WithRateLimiter(nil), WithMiddleware(RequestIDMiddleware(nil)))
+	_, err := c.Contest.List(false)
+	assert.Nil(t, err)
+	_, err = c.Contest.ListCtx(ContextWithRequestID(context.Background(), "mine"), false)
+	assert.Nil(t, err)
+	assert.Len(t, ids, 2)
+	assert.Len(t, ids[0], 16)
+	assert.Equal(t, "mine", ids[1])
+}
@@ -0,0 +1,164 @@
+package codeforces
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// configures the client created by NewClient
+type ClientOption func(*httpClientWrapper)
+
+// replaces the default per-client rate limiter. Passing SharedRateLimiter()
+// to several clients makes them share one call budget, while nil disables
+// rate limiting altogether.
+func WithRateLimiter(l *RateLimiter) ClientOption {
+	return func(c *httpClientWrapper) {
+		c.limiter = l
+	}
+}
+
+// replaces DefaultRetryPolicy, nil disables retries
+func WithRetryPolicy(p *RetryPolicy) ClientOption {
+	return func(c *httpClientWrapper) {
+		c.retry = p
+	}
+}
+
+// uses hc instead of http.DefaultClient to perform requests, nil keeps
+// http.DefaultClient
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *httpClientWrapper) {
+		if hc == nil {
+			hc = http.DefaultClient
+		}
+		c.http.client = hc
+	}
+}
+
+// points the client to a different api root, such as a mirror or a local
+// server. The url should include the api path, e.g. "http://localhost:8080/api/"
+func WithBaseURL(baseURL string) ClientOption {
+	return WithBaseURLs(baseURL)
+}
+
+// sends calls to the first of baseURLs that works. A base url that can't be
+// reached or answers with a server error is skipped for a while, see
+// WithFailoverCooldown, and the call is sent to the next one right away.
+// Hooks can find out which host served each call through CallInfo.Host. An
+// empty list keeps the current base urls.
+func WithBaseURLs(baseURLs ...string) ClientOption {
+	return func(c *httpClientWrapper) {
+		if len(baseURLs) == 0 {
+			return
+		}
+		c.http.endpoints = newEndpoints(baseURLs...)
+	}
+}
+
+// sets for how long a failing base url is skipped, DefaultFailoverCooldown by
+// default
+func WithFailoverCooldown(d time.Duration) ClientOption {
+	return func(c *httpClientWrapper) {
+		c.http.failoverCooldown = d
+	}
+}
+
+// sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *httpClientWrapper) {
+		c.http.userAgent = userAgent
+	}
+}
+
+// limits the duration of every single http request. The http client passed to
+// WithHTTPClient is copied, not modified.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *httpClientWrapper) {
+		c.timeout = d
+	}
+}
+
+// logs every api call at debug level, and retries at warn level
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *httpClientWrapper) {
+		c.logger = logger
+	}
+}
+
+// sends the lang parameter (e.g. "en" or "ru") with every call, which changes
+// the language of contest names, problem names and blog titles. Single calls
+// can use another language through ContextWithLanguage. The default is added by
+// the outermost layer of NewTransport, so middlewares see it, and a
+// CachingTransport built directly on top of it keys its entries by it.
+func WithLanguage(lang string) ClientOption {
+	return func(c *httpClientWrapper) {
+		c.lang = lang
+	}
+}
+
+// replaces the transport performing each single attempt, which by default signs
+// calls and sends them over http. Rate limiting and retries still apply on top
+// of t. Options configuring the http layer have no effect on a custom transport.
+func WithTransport(t Transport) ClientOption {
+	return func(c *httpClientWrapper) {
+		c.transport = t
+	}
+}
+
+// makes calls fail with ErrResponseTooLarge once their response body grows
+// past n bytes. Responses aren't limited by default.
+func WithMaxResponseSize(n int64) ClientOption {
+	return func(c *httpClientWrapper) {
+		c.http.maxResponseSize = n
+	}
+}
+
+// controls whether identical calls made at the same time share a single
+// request and its result. Enabled by default.
+func WithDeduplication(enabled bool) ClientOption {
+	return func(c *httpClientWrapper) {
+		c.dedup = enabled
+	}
+}
+
+// records the calls of the client with r, or replays them. r is placed below
+// the signing layer, in front of the http transport configured by the other
+// options, which becomes its Next unless already set. A client in ModeReplay
+// never contacts the api, so its rate limiter is disabled.
+func WithRecorder(r *Recorder) ClientOption {
+	return func(c *httpClientWrapper) {
+		if t, ok := c.transport.(*SigningTransport); ok {
+			if r.Next == nil {
+				r.Next = t.Next
+			}
+			t.Next = r
+		} else {
+			if r.Next == nil {
+				r.Next = c.transport
+			}
+			c.transport = r
+		}
+		if r.mode == ModeReplay {
+			c.limiter = nil
+		}
+	}
+}
+
+// adds middlewares around every call made by the client, the first one being
+// the outermost. They see each call once, however many attempts it takes.
+func WithMiddleware(mws ...Middleware) ClientOption {
+	return func(c *httpClientWrapper) {
+		c.middlewares = append(c.middlewares, mws...)
+	}
+}
+
+// records the calls of the client into m, see Metrics. Unlike adding
+// m.Middleware() with WithMiddleware, the rate limiter wait time is recorded
+// as well.
+func WithMetrics(m *Metrics) ClientOption {
+	return func(c *httpClientWrapper) {
+		c.metrics = m
+		c.middlewares = append(c.middlewares, m.Middleware())
+	}
+}
@@ -0,0 +1,116 @@
+package codeforces
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientOptions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/problemset.problems", r.URL.Path)
+		assert.Equal(t, "ru", r.URL.Query().Get("lang"))
+		assert.Equal(t, "cf-test", r.Header.Get("User-Agent"))
+		w.WriteHeader(200)
+		_, err := w.Write([]byte(`{"status":"OK","result":{"problems":[],"problemStatistics":[]}}`))
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := NewClient("", "",
+		WithBaseURL(ts.URL+"/api"),
+		WithHTTPClient(ts.Client()),
+		WithUserAgent("cf-test"),
+		WithLanguage("ru"),
+		WithLogger(logger),
+		WithRateLimiter(nil),
+		WithRetryPolicy(nil),
+	)
+	resp, err := c.Problems.Problemset(nil)
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+	assert.Contains(t, logs.String(), "method=problemset.problems")
+}
+
+func TestWithTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-unblock:
+		}
+	}))
+	defer ts.Close()
+	defer close(unblock)
+	hc := &http.Client{}
+	c := NewClient("", "",
+		WithBaseURL(ts.URL),
+		WithTimeout(20*time.Millisecond),
+		WithHTTPClient(hc),
+		WithRateLimiter(nil),
+		WithRetryPolicy(nil),
+	)
+	resp, err := c.Contest.List(false)
+	assert.Nil(t, resp)
+	assert.NotNil(t, err)
+	assert.Zero(t, hc.Timeout)
+}
+
+func TestNilHTTPClient(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"OK","result":[]}`))
+	}))
+	defer ts.Close()
+	for _, opts := range [][]ClientOption{
+		{WithHTTPClient(nil)},
+		{WithHTTPClient(nil), WithTimeout(time.Second)},
+	} {
+		c := NewClient("", "", append(opts, WithBaseURL(ts.URL), WithRateLimiter(nil), WithRetryPolicy(nil))...)
+		_, err := c.Contest.List(false)
+		assert.Nil(t, err)
+	}
+	assert.Zero(t, http.DefaultClient.Timeout)
+}
+
+func TestLanguage(t *testing.T) {
+	langs := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		langs[path.Base(r.URL.Path)] = r.URL.Query().Get("lang")
+		result := `{}`
+		if r.URL.Path == "/api/contest.list" {
+			result = `[]`
+		}
+		_, err := w.Write([]byte(`{"status":"OK","result":` + result + `}`))
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := NewClient("", "", WithBaseURL(ts.URL+"/api"), WithLanguage("ru"), WithRateLimiter(nil), WithRetryPolicy(nil))
+	calls := func(ctx context.Context) {
+		_, err := c.Contest.ListCtx(ctx, false)
+		assert.Nil(t, err)
+		_, err = c.Problems.ProblemsetCtx(ctx, nil)
+		assert.Nil(t, err)
+		_, err = c.Contest.StandingsCtx(ctx, 566, 1, 10, nil, false)
+		assert.Nil(t, err)
+		_, err = c.Blog.EntryByIdCtx(ctx, 79)
+		assert.Nil(t, err)
+	}
+	methods := []string{"contest.list", "problemset.problems", "contest.standings", "blogEntry.view"}
+
+	calls(context.Background())
+	for _, m := range methods {
+		assert.Equal(t, "ru", langs[m], m)
+	}
+	calls(ContextWithLanguage(context.Background(), "en"))
+	for _, m := range methods {
+		assert.Equal(t, "en", langs[m], m)
+	}
+}
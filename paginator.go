@@ -0,0 +1,70 @@
+package codeforces
+
+import "context"
+
+// number of entries a paginator requests with every call, unless changed
+const DefaultPageSize = 1000
+
+// Paginator walks an endpoint taking from/count parameters, fetching one page
+// per call and only when asked to. Calls go through the client transport, so
+// they respect its rate limiter. A Paginator is not safe for concurrent use.
+type Paginator[T any] struct {
+	// number of entries requested with every call, DefaultPageSize when 0
+	PageSize uint
+	fetch    func(ctx context.Context, from, count uint) ([]T, error)
+	// 1-based index of the first entry of the next page
+	from uint
+	done bool
+}
+
+func newPaginator[T any](fetch func(ctx context.Context, from, count uint) ([]T, error)) *Paginator[T] {
+	return &Paginator[T]{PageSize: DefaultPageSize, fetch: fetch, from: 1}
+}
+
+// reports whether there might be more pages. The end of the data is only
+// detected once a page shorter than PageSize is returned.
+func (p *Paginator[T]) HasMore() bool {
+	return !p.done
+}
+
+func (p *Paginator[T]) Next() ([]T, error) {
+	return p.NextCtx(context.Background())
+}
+
+// fetches the next page. Once there are no more pages it returns nil. After an
+// error the same page is requested again by the next call.
+func (p *Paginator[T]) NextCtx(ctx context.Context) ([]T, error) {
+	if p.done {
+		return nil, nil
+	}
+	size := p.PageSize
+	if size == 0 {
+		size = DefaultPageSize
+	}
+	page, err := p.fetch(ctx, p.from, size)
+	if err != nil {
+		return nil, err
+	}
+	p.from += uint(len(page))
+	if uint(len(page)) < size {
+		p.done = true
+	}
+	return page, nil
+}
+
+func (p *Paginator[T]) All() ([]T, error) {
+	return p.AllCtx(context.Background())
+}
+
+// collects every remaining page
+func (p *Paginator[T]) AllCtx(ctx context.Context) ([]T, error) {
+	var all []T
+	for p.HasMore() {
+		page, err := p.NextCtx(ctx)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+	}
+	return all, nil
+}
@@ -0,0 +1,130 @@
+package codeforces
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// serves total submissions with increasing ids, honoring from and count
+func pagedStatusServer(t *testing.T, total int, calls *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		from, _ := strconv.Atoi(r.URL.Query().Get("from"))
+		count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+		result := []ContestStatus{}
+		for id := from; id < from+count && id <= total; id++ {
+			result = append(result, ContestStatus{ID: id})
+		}
+		w.WriteHeader(200)
+		assert.Nil(t, json.NewEncoder(w).Encode(ResultWrapper[[]ContestStatus]{Status: "OK", Result: result}))
+	}))
+}
+
+func ids(statuses []ContestStatus) []int {
+	out := []int{}
+	for _, s := range statuses {
+		out = append(out, s.ID)
+	}
+	return out
+}
+
+func TestAllStatusPages(t *testing.T) {
+	calls := 0
+	ts := pagedStatusServer(t, 5, &calls)
+	defer ts.Close()
+	cs := contestService{newDefaultClientWrapper(ts.URL+"/", "", "")}
+	p := cs.AllStatus(566)
+	p.PageSize = 2
+	var pages [][]int
+	for p.HasMore() {
+		page, err := p.Next()
+		assert.Nil(t, err)
+		pages = append(pages, ids(page))
+	}
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, pages)
+	assert.Equal(t, 3, calls)
+	page, err := p.Next()
+	assert.Nil(t, err)
+	assert.Nil(t, page)
+	assert.Equal(t, 3, calls)
+}
+
+func TestAllStatusExactMultiple(t *testing.T) {
+	calls := 0
+	ts := pagedStatusServer(t, 4, &calls)
+	defer ts.Close()
+	cs := contestService{newDefaultClientWrapper(ts.URL+"/", "", "")}
+	p := cs.AllStatusWithHandle(566, "tourist")
+	p.PageSize = 2
+	all, err := p.All()
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4}, ids(all))
+	assert.Equal(t, 3, calls)
+	assert.False(t, p.HasMore())
+}
+
+func TestPaginatorRetriesFailedPage(t *testing.T) {
+	fail := true
+	var froms []uint
+	p := newPaginator(func(ctx context.Context, from, count uint) ([]int, error) {
+		froms = append(froms, from)
+		if from == 3 && fail {
+			fail = false
+			return nil, errors.New("boom")
+		}
+		if from > 4 {
+			return nil, nil
+		}
+		return []int{int(from), int(from) + 1}, nil
+	})
+	p.PageSize = 2
+	_, err := p.All()
+	assert.NotNil(t, err)
+	assert.True(t, p.HasMore())
+	rest, err := p.All()
+	assert.Nil(t, err)
+	assert.Equal(t, []int{3, 4}, rest)
+	assert.Equal(t, []uint{1, 3, 3, 5}, froms)
+}
+
+func TestStandingsPages(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from, _ := strconv.Atoi(r.URL.Query().Get("from"))
+		rows := []Row{}
+		if from == 1 {
+			rows = append(rows, Row{Rank: 1}, Row{Rank: 2})
+		} else if from == 3 {
+			rows = append(rows, Row{Rank: 3})
+		}
+		w.WriteHeader(200)
+		assert.Nil(t, json.NewEncoder(w).Encode(ResultWrapper[ContestStandings]{Status: "OK", Result: ContestStandings{Rows: rows}}))
+	}))
+	defer ts.Close()
+	cs := contestService{newDefaultClientWrapper(ts.URL+"/", "", "")}
+	p := cs.StandingsPages(566, nil, false)
+	p.PageSize = 2
+	rows, err := p.All()
+	assert.Nil(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, 3, rows[2].Rank)
+}
+
+func TestUserAllStatus(t *testing.T) {
+	calls := 0
+	ts := pagedStatusServer(t, 3, &calls)
+	defer ts.Close()
+	us := userService{newDefaultClientWrapper(ts.URL+"/", "", "")}
+	p := us.AllStatus("tourist")
+	p.PageSize = 2
+	all, err := p.All()
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, ids(all))
+	assert.Equal(t, 2, calls)
+}
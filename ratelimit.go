@@ -0,0 +1,121 @@
+package codeforces
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Codeforces allows roughly one call every two seconds per client,
+// going faster than this results in "Call limit exceeded" failures.
+const DefaultCallInterval = 2 * time.Second
+
+// RateLimiter spaces out calls so that at most one of them starts every interval.
+// Waiting callers are served in the order they arrived, and the ones queued
+// behind a caller that gives up move forward into its slot. It is safe for
+// concurrent use, so the same limiter can be shared by several clients.
+type RateLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+	// callers waiting for their slot, in order
+	queue []*waiter
+}
+
+type waiter struct {
+	slot time.Time
+	// signalled when slot moves earlier
+	moved chan struct{}
+}
+
+func NewRateLimiter(interval time.Duration) *RateLimiter {
+	return &RateLimiter{interval: interval}
+}
+
+var sharedRateLimiter = NewRateLimiter(DefaultCallInterval)
+
+// returns a process wide limiter. Clients configured with it share a single
+// call budget instead of having one each.
+func SharedRateLimiter() *RateLimiter {
+	return sharedRateLimiter
+}
+
+// Wait blocks until the caller is allowed to make a call, or until ctx is done.
+// A nil limiter never blocks.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	w := l.reserve()
+	if w == nil {
+		return nil
+	}
+	for {
+		delay := time.Until(l.slot(w))
+		if delay <= 0 {
+			l.leave(w, false)
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-w.moved:
+		case <-ctx.Done():
+			timer.Stop()
+			l.leave(w, true)
+			return ctx.Err()
+		}
+		timer.Stop()
+	}
+}
+
+// takes the first free slot, and pushes the following one forward. Returns
+// nil when the slot is now, otherwise the caller is queued until it leaves.
+func (l *RateLimiter) reserve() *waiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if !l.next.After(now) {
+		l.next = now.Add(l.interval)
+		return nil
+	}
+	w := &waiter{slot: l.next, moved: make(chan struct{}, 1)}
+	l.queue = append(l.queue, w)
+	l.next = l.next.Add(l.interval)
+	return w
+}
+
+func (l *RateLimiter) slot(w *waiter) time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return w.slot
+}
+
+// removes w from the queue. When it gives up before its slot, the callers
+// queued after it move one slot forward, and so does the next free one.
+func (l *RateLimiter) leave(w *waiter, cancelled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	i := 0
+	for i < len(l.queue) && l.queue[i] != w {
+		i++
+	}
+	if i == len(l.queue) {
+		return
+	}
+	l.queue = append(l.queue[:i], l.queue[i+1:]...)
+	if !cancelled || !w.slot.After(time.Now()) {
+		return
+	}
+	for _, later := range l.queue[i:] {
+		later.slot = later.slot.Add(-l.interval)
+		select {
+		case later.moved <- struct{}{}:
+		default:
+		}
+	}
+	l.next = l.next.Add(-l.interval)
+}
@@ -0,0 +1,114 @@
+package codeforces
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterSpacesCalls(t *testing.T) {
+	interval := 20 * time.Millisecond
+	l := NewRateLimiter(interval)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, l.Wait(context.Background()))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 3*interval)
+}
+
+func TestRateLimiterKeepsOrder(t *testing.T) {
+	l := NewRateLimiter(10 * time.Millisecond)
+	assert.Nil(t, l.Wait(context.Background()))
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		slot := l.reserve().slot
+		wg.Add(1)
+		go func(i int, slot time.Time) {
+			defer wg.Done()
+			time.Sleep(time.Until(slot))
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		}(i, slot)
+	}
+	wg.Wait()
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+}
+
+func TestRateLimiterCancelled(t *testing.T) {
+	interval := time.Hour
+	l := NewRateLimiter(interval)
+	assert.Nil(t, l.Wait(context.Background()))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.Wait(ctx), context.DeadlineExceeded)
+	// the cancelled caller gives its slot back
+	assert.WithinDuration(t, time.Now().Add(interval), l.reserve().slot, time.Minute)
+}
+
+func TestRateLimiterCancelledMovesQueue(t *testing.T) {
+	interval := 50 * time.Millisecond
+	l := NewRateLimiter(interval)
+	assert.Nil(t, l.Wait(context.Background()))
+	waiting := func() int {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return len(l.queue)
+	}
+	start := time.Now()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() { cancelled <- l.Wait(ctx) }()
+	// queue up behind the caller that gives up
+	for waiting() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	done := make(chan time.Duration)
+	go func() {
+		assert.Nil(t, l.Wait(context.Background()))
+		done <- time.Since(start)
+	}()
+	for waiting() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	assert.ErrorIs(t, <-cancelled, context.Canceled)
+	waited := <-done
+	assert.GreaterOrEqual(t, waited, interval-5*time.Millisecond)
+	assert.Less(t, waited, 2*interval-10*time.Millisecond)
+}
+
+func TestNilRateLimiter(t *testing.T) {
+	var l *RateLimiter
+	assert.Nil(t, l.Wait(context.Background()))
+}
+
+func TestSharedRateLimiterBetweenClients(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		_, err := w.Write([]byte(`{"status":"OK","result":[]}`))
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	interval := 30 * time.Millisecond
+	l := NewRateLimiter(interval)
+	first := newDefaultClientWrapper(ts.URL+"/", "", "")
+	second := newDefaultClientWrapper(ts.URL+"/", "", "")
+	WithRateLimiter(l)(first)
+	WithRateLimiter(l)(second)
+	start := time.Now()
+	_, err := (&contestService{first}).List(false)
+	assert.Nil(t, err)
+	_, err = (&contestService{second}).List(false)
+	assert.Nil(t, err)
+	_, err = (&contestService{first}).List(false)
+	assert.Nil(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 2*interval)
+}
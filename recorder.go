@@ -0,0 +1,182 @@
+package codeforces
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// returned by a replaying Recorder for calls missing from its cassette
+var ErrNotRecorded = errors.New("call not recorded")
+
+// RecorderMode decides whether a Recorder contacts the api.
+type RecorderMode int
+
+const (
+	// answers every call from the cassette, failing with ErrNotRecorded
+	// for unknown ones. Next is never used.
+	ModeReplay RecorderMode = iota
+	// passes every call to Next and records it, discarding the previous
+	// content of the cassette
+	ModeRecord
+	// answers known calls from the cassette, and records the others
+	ModeReplayOrRecord
+)
+
+// Recorder records the calls passed to Next into a cassette file, and replays
+// them afterwards without contacting the api, to make tests deterministic.
+// Calls are matched on method and parameters, ignoring apiKey, apiSig and
+// time, which are never written to the cassette. Api errors are recorded as
+// well, other failures aren't. The cassette is saved after every recorded
+// call.
+//
+// A Recorder is meant to sit below a SigningTransport, in place of the
+// default http transport; see WithRecorder.
+type Recorder struct {
+	Next Transport
+	path string
+	mode RecorderMode
+
+	mu           sync.Mutex
+	interactions []interaction
+	// how many times each recorded call was replayed, so that repeated calls
+	// are answered in the order they were recorded
+	replayed map[string]int
+}
+
+// the content of a cassette file
+type cassette struct {
+	Interactions []interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Method string            `json:"method"`
+	Params map[string]string `json:"params,omitempty"`
+	// 200 along with Body for successful calls, the status of the APIError
+	// along with its Comment otherwise
+	StatusCode int             `json:"statusCode"`
+	Comment    string          `json:"comment,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+}
+
+func (i *interaction) key() string {
+	return cacheKey(i.Method, i.Params)
+}
+
+// loads the cassette at path, unless mode is ModeRecord. A missing cassette
+// is only an error in ModeReplay.
+func NewRecorder(path string, mode RecorderMode, next Transport) (*Recorder, error) {
+	r := &Recorder{Next: next, path: path, mode: mode, replayed: map[string]int{}}
+	if mode == ModeRecord {
+		return r, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && mode == ModeReplayOrRecord {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("cassette %s: %w", path, err)
+	}
+	r.interactions = c.Interactions
+	return r, nil
+}
+
+func (r *Recorder) Get(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+	if r.mode != ModeRecord {
+		if i, ok := r.lookup(method, params); ok {
+			return i.response(params)
+		}
+		if r.mode == ModeReplay {
+			return nil, fmt.Errorf("%w: %s", ErrNotRecorded, cacheKey(method, params))
+		}
+	}
+	resp, err := r.Next.Get(ctx, method, params)
+	i := interaction{Method: method, Params: publicParams(params), StatusCode: http.StatusOK}
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr):
+		i.StatusCode = apiErr.StatusCode
+		i.Comment = apiErr.Comment
+	case err != nil:
+		return nil, err
+	default:
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		i.Body = body
+		resp = syntheticResponse(method, body)
+	}
+	if saveErr := r.record(i); saveErr != nil {
+		return nil, saveErr
+	}
+	return resp, err
+}
+
+// the first recorded answer to the call that wasn't replayed yet, or the last
+// one when all of them were
+func (r *Recorder) lookup(method string, params map[string]string) (interaction, bool) {
+	key := cacheKey(method, params)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var matches []interaction
+	for _, i := range r.interactions {
+		if i.key() == key {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		return interaction{}, false
+	}
+	n := r.replayed[key]
+	r.replayed[key]++
+	return matches[min(n, len(matches)-1)], true
+}
+
+func (i *interaction) response(params map[string]string) (*http.Response, error) {
+	if i.StatusCode != http.StatusOK || i.Body == nil {
+		return nil, &APIError{StatusCode: i.StatusCode, Method: i.Method, Comment: i.Comment, Params: publicParams(params)}
+	}
+	return syntheticResponse(i.Method, i.Body), nil
+}
+
+// appends i to the cassette and saves it
+func (r *Recorder) record(i interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, i)
+	data, err := json.MarshalIndent(cassette{Interactions: r.interactions}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(r.path), ".cassette-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), r.path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package codeforces
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderRecordAndReplay(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("handles") == "nobody" {
+			w.WriteHeader(400)
+			_, _ = w.Write([]byte(`{"status":"FAILED","comment":"handles: User with handle nobody not found"}`))
+			return
+		}
+		b, err := os.ReadFile("testdata/user/info/singleuser.json")
+		assert.Nil(t, err)
+		_, _ = w.Write(b)
+	}))
+	defer ts.Close()
+	path := filepath.Join(t.TempDir(), "cassettes", "user.json")
+
+	rec, err := NewRecorder(path, ModeRecord, nil)
+	assert.Nil(t, err)
+	c := NewClient("mykey", "mysecret", WithBaseURL(ts.URL), WithRateLimiter(nil), WithRetryPolicy(nil), WithRecorder(rec))
+	recorded, err := c.User.Info([]string{"tourist"})
+	assert.Nil(t, err)
+	_, err = c.User.Info([]string{"nobody"})
+	assert.True(t, errors.Is(err, ErrHandleNotFound))
+	assert.Equal(t, 2, calls)
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	for _, secret := range []string{"mykey", "mysecret", "apiKey", "apiSig", `"time"`} {
+		assert.False(t, strings.Contains(string(data), secret), secret)
+	}
+
+	// different credentials, and the server isn't contacted anymore
+	rec, err = NewRecorder(path, ModeReplay, nil)
+	assert.Nil(t, err)
+	c = NewClient("otherkey", "othersecret", WithRecorder(rec))
+	replayed, err := c.User.Info([]string{"tourist"})
+	assert.Nil(t, err)
+	assert.Equal(t, recorded, replayed)
+	_, err = c.User.Info([]string{"nobody"})
+	assert.True(t, errors.Is(err, ErrHandleNotFound))
+	_, err = c.User.Info([]string{"benq"})
+	assert.True(t, errors.Is(err, ErrNotRecorded))
+	assert.Equal(t, 2, calls)
+}
+
+func TestRecorderReplayOrRecord(t *testing.T) {
+	calls := 0
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	bodies := []string{`{"status":"OK","result":[{"id":1}]}`, `{"status":"OK","result":[{"id":2}]}`}
+	next := TransportFunc(func(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+		calls++
+		return jsonResponse(bodies[calls-1]), nil
+	})
+	_, err := NewRecorder(path, ModeReplay, next)
+	assert.NotNil(t, err)
+
+	rec, err := NewRecorder(path, ModeRecord, next)
+	assert.Nil(t, err)
+	cs := contestService{rec}
+	for _, id := range []int{1, 2} {
+		resp, err := cs.List(false)
+		assert.Nil(t, err)
+		assert.Equal(t, id, (*resp)[0].ID)
+	}
+
+	// repeated calls are replayed in order, the last answer sticks
+	rec, err = NewRecorder(path, ModeReplayOrRecord, next)
+	assert.Nil(t, err)
+	cs = contestService{rec}
+	for _, id := range []int{1, 2, 2} {
+		resp, err := cs.List(false)
+		assert.Nil(t, err)
+		assert.Equal(t, id, (*resp)[0].ID)
+	}
+	assert.Equal(t, 2, calls)
+}
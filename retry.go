@@ -0,0 +1,100 @@
+package codeforces
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy decides whether a failed call is attempted again, and how long
+// to wait before doing so. Every attempt is signed again, so it carries a
+// fresh time and apiSig.
+type RetryPolicy struct {
+	// total number of attempts, including the first one
+	MaxAttempts int
+	// delay before the first retry, doubled on every following one
+	BaseDelay time.Duration
+	// upper bound for the delay between two attempts
+	MaxDelay time.Duration
+	// reports whether err is worth retrying, IsRetryable is used when nil
+	Retryable func(err error) bool
+}
+
+// retries transient failures twice, starting from a delay comparable to
+// the api call limit
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   DefaultCallInterval,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// IsRetryable reports whether err is a transient failure: a network error,
+// a 5xx response or an exceeded call limit. Errors caused by the request
+// itself, such as a handle that doesn't exist, an unsupported url scheme or a
+// certificate that can't be verified, are considered fatal.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 || errors.Is(apiErr, ErrCallLimitExceeded)
+	}
+	// http.Client wraps all of its errors, fatal ones included, in a url.Error
+	// that is a net.Error itself, so only the cause tells them apart
+	var netErr net.Error
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return errors.As(err, &netErr)
+	}
+	cause := urlErr.Err
+	return errors.As(cause, &netErr) ||
+		errors.Is(cause, io.EOF) ||
+		errors.Is(cause, io.ErrUnexpectedEOF) ||
+		errors.Is(cause, syscall.ECONNRESET) ||
+		errors.Is(cause, syscall.ECONNREFUSED)
+}
+
+func (p *RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// exponential backoff with jitter, the result lies in [d/2, d)
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 1 {
+		return d
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)))
+}
+
+// waits before the next attempt, returning early if ctx is done
+func (p *RetryPolicy) sleep(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(p.backoff(attempt))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,123 @@
+package codeforces
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fastRetryPolicy(attempts int) *RetryPolicy {
+	return &RetryPolicy{MaxAttempts: attempts, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+}
+
+func TestRetryCallLimitExceeded(t *testing.T) {
+	var signatures []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signatures = append(signatures, r.URL.Query().Get("apiSig"))
+		if len(signatures) == 1 {
+			w.WriteHeader(503)
+			_, err := w.Write([]byte(`{"status":"FAILED","comment":"Call limit exceeded"}`))
+			assert.Nil(t, err)
+			return
+		}
+		w.WriteHeader(200)
+		_, err := w.Write([]byte(`{"status":"OK","result":["tourist"]}`))
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "key", "secret")
+	WithRetryPolicy(fastRetryPolicy(3))(c)
+	us := userService{c}
+	resp, err := us.Friends(false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"tourist"}, *resp)
+	assert.Len(t, signatures, 2)
+	assert.NotEqual(t, signatures[0], signatures[1])
+}
+
+func TestRetryFatalError(t *testing.T) {
+	hits := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(400)
+		_, err := w.Write([]byte(`{"status":"FAILED","comment":"handles: User with handle nobody not found"}`))
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	WithRetryPolicy(fastRetryPolicy(3))(c)
+	us := userService{c}
+	resp, err := us.Info([]string{"nobody"})
+	assert.Nil(t, resp)
+	assert.EqualError(t, err, "400:handles: User with handle nobody not found")
+	assert.Equal(t, 1, hits)
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	hits := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(503)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	WithRetryPolicy(fastRetryPolicy(4))(c)
+	cs := contestService{c}
+	resp, err := cs.List(false)
+	assert.Nil(t, resp)
+	assert.NotNil(t, err)
+	assert.Equal(t, 4, hits)
+}
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		err       error
+		retryable bool
+	}{
+		{err: &APIError{StatusCode: 503, Comment: "Service Unavailable"}, retryable: true},
+		{err: &APIError{StatusCode: 400, Comment: "Call limit exceeded"}, retryable: true},
+		{err: &APIError{StatusCode: 400, Comment: "handles: User with handle x not found"}, retryable: false},
+		{err: &url.Error{Op: "Get", URL: "", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}, retryable: true},
+		{err: &url.Error{Op: "Get", URL: "", Err: syscall.ECONNRESET}, retryable: true},
+		{err: &url.Error{Op: "Get", URL: "", Err: io.ErrUnexpectedEOF}, retryable: true},
+		{err: &url.Error{Op: "Get", URL: "", Err: context.Canceled}, retryable: false},
+		{err: &url.Error{Op: "Get", URL: "htp://x/", Err: errors.New(`unsupported protocol scheme "htp"`)}, retryable: false},
+		{err: &url.Error{Op: "Get", URL: "", Err: x509.UnknownAuthorityError{}}, retryable: false},
+		{err: errors.New("something else"), retryable: false},
+	}
+	for _, tt := range cases {
+		assert.Equal(t, tt.retryable, IsRetryable(tt.err), tt.err.Error())
+	}
+}
+
+func TestNoRetryOnMisconfiguration(t *testing.T) {
+	c := NewClient("", "", WithBaseURL("htp://x/"), WithRateLimiter(nil),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := c.Contest.ListCtx(ctx, false)
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for i := 0; i < 20; i++ {
+		first := p.backoff(1)
+		assert.GreaterOrEqual(t, first, 50*time.Millisecond)
+		assert.Less(t, first, 100*time.Millisecond)
+		capped := p.backoff(5)
+		assert.GreaterOrEqual(t, capped, 150*time.Millisecond)
+		assert.Less(t, capped, 300*time.Millisecond)
+	}
+}
@@ -0,0 +1,60 @@
+package codeforces
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fixedSigner() Signer {
+	return Signer{
+		Key:    "xxx",
+		Secret: "yyy",
+		Now:    func() time.Time { return time.Unix(1234567890, 0) },
+		Rand:   func() int { return 23456 },
+	}
+}
+
+// example from https://codeforces.com/apiHelp
+func TestSignerDeterministic(t *testing.T) {
+	params := url.Values{"contestId": {"566"}}
+	signed := fixedSigner().Sign("contest.hacks", params)
+	assert.Equal(t, "xxx", signed.Get("apiKey"))
+	assert.Equal(t, "1234567890", signed.Get("time"))
+	assert.Equal(t, "566", signed.Get("contestId"))
+	// sha512 of "123456/contest.hacks?apiKey=xxx&contestId=566&time=1234567890#yyy"
+	expected := "123456" + "7f467d1cd837599d2f0dc9fd8beec8fad80ee7d02f0b65ad153a963bca2923de" +
+		"885e11c96cba96beceaba6dd7433d20c0cbb507b7615b3dccfb693b6163ccc94"
+	assert.Equal(t, expected, signed.Get("apiSig"))
+	// the input isn't modified
+	assert.Equal(t, url.Values{"contestId": {"566"}}, params)
+}
+
+func TestSignerRandomPrefixInRange(t *testing.T) {
+	for _, r := range []int{0, -5, 999999, 1 << 40} {
+		s := fixedSigner()
+		s.Rand = func() int { return r }
+		prefix := s.Sign("user.info", nil).Get("apiSig")[:6]
+		assert.Len(t, prefix, 6)
+		assert.NotEqual(t, byte('0'), prefix[0])
+	}
+}
+
+func TestVerify(t *testing.T) {
+	signed := fixedSigner().Sign("user.friends", url.Values{"onlyOnline": {"true"}})
+	assert.Nil(t, Verify("user.friends", signed, "yyy"))
+	assert.ErrorIs(t, Verify("user.friends", signed, "wrong"), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("user.info", signed, "yyy"), ErrInvalidSignature)
+
+	tampered := url.Values{}
+	for k, v := range signed {
+		tampered[k] = v
+	}
+	tampered.Set("onlyOnline", "false")
+	assert.ErrorIs(t, Verify("user.friends", tampered, "yyy"), ErrInvalidSignature)
+
+	tampered.Del("apiSig")
+	assert.ErrorIs(t, Verify("user.friends", tampered, "yyy"), ErrInvalidSignature)
+}
@@ -0,0 +1,158 @@
+package codeforces
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// walks the api envelope ({"status": ..., "comment": ..., "result": ...}) and
+// hands the decoder to decodeResult once it's positioned on the result.
+// A json.Decoder buffers every value it decodes as a whole, so the result is
+// never decoded in a single call.
+func decodeEnvelope(dec *json.Decoder, decodeResult func(dec *json.Decoder) error) (status, comment string, err error) {
+	if err := expectDelim(dec, '{'); err != nil {
+		return "", "", err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return "", "", err
+		}
+		switch key {
+		case "status":
+			err = dec.Decode(&status)
+		case "comment":
+			err = dec.Decode(&comment)
+		case "result":
+			err = decodeResult(dec)
+		default:
+			err = dec.Decode(&json.RawMessage{})
+		}
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return status, comment, expectDelim(dec, '}')
+}
+
+// decodes the next value into v, which must be addressable. Arrays are decoded
+// one element at a time, and so are the arrays held by a top level object
+// (e.g. the problems of a Problemset), so that the decoder never needs to
+// buffer more than a single element.
+func streamValue(dec *json.Decoder, v reflect.Value, nested bool) error {
+	switch {
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8:
+		return streamArray(dec, v)
+	case v.Kind() == reflect.Struct && !nested:
+		return streamObject(dec, v)
+	}
+	return dec.Decode(v.Addr().Interface())
+}
+
+func streamArray(dec *json.Decoder, v reflect.Value) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("expected array, found %v", tok)
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	}
+	zero := reflect.Zero(v.Type().Elem())
+	for dec.More() {
+		// decoding straight into the new slot saves an allocation per element
+		v.Set(reflect.Append(v, zero))
+		if err := dec.Decode(v.Index(v.Len() - 1).Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+// decodes an array one element at a time, passing each of them to fn
+func eachElement[T any](dec *json.Decoder, fn func(T) error) error {
+	tok, err := dec.Token()
+	if err != nil || tok == nil {
+		return err
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("expected array, found %v", tok)
+	}
+	for dec.More() {
+		var elem T
+		if err := dec.Decode(&elem); err != nil {
+			return err
+		}
+		if err := fn(elem); err != nil {
+			return &callbackError{err}
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func streamObject(dec *json.Decoder, v reflect.Value) error {
+	tok, err := dec.Token()
+	if err != nil || tok == nil {
+		return err
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf("expected object, found %v", tok)
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		name, _ := key.(string)
+		if field, ok := fieldByJSONName(v, name); ok {
+			err = streamValue(dec, field, true)
+		} else {
+			err = dec.Decode(&json.RawMessage{})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// mimics encoding/json: the tag name is preferred, and matched case insensitively
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tagName, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tagName == "-" {
+			continue
+		}
+		if tagName == "" {
+			tagName = f.Name
+		}
+		if strings.EqualFold(tagName, name) {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("expected %v, found %v", delim, tok)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package codeforces
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeString[T any](body string) (ResultWrapper[T], error) {
+	rw := ResultWrapper[T]{}
+	dec := json.NewDecoder(strings.NewReader(body))
+	var err error
+	rw.Status, rw.Comment, err = decodeEnvelope(dec, func(dec *json.Decoder) error {
+		return streamValue(dec, reflect.ValueOf(&rw.Result).Elem(), false)
+	})
+	return rw, err
+}
+
+func TestStreamValue(t *testing.T) {
+	rw, err := decodeString[[]string](`{"status":"OK","result":["tourist","benq"]}`)
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", rw.Status)
+	assert.Equal(t, []string{"tourist", "benq"}, rw.Result)
+
+	empty, err := decodeString[[]string](`{"result":[],"status":"OK"}`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, empty.Result)
+
+	null, err := decodeString[[]string](`{"status":"OK","result":null}`)
+	assert.Nil(t, err)
+	assert.Nil(t, null.Result)
+
+	ps, err := decodeString[Problemset](`{"status":"OK","extra":{"a":[1]},"result":{
+		"PROBLEMS":[{"contestId":1,"index":"A","tags":["math"]}],
+		"unknown":[1,2,3],
+		"problemStatistics":[{"contestId":1,"index":"A","solvedCount":5}]}}`)
+	assert.Nil(t, err)
+	assert.Equal(t, []Problem{{ContestID: 1, Index: "A", Tags: []string{"math"}}}, ps.Result.Problems)
+	assert.Equal(t, 5, ps.Result.ProblemStatistics[0].SolvedCount)
+
+	failed, err := decodeString[[]string](`{"status":"FAILED","comment":"Call limit exceeded"}`)
+	assert.Nil(t, err)
+	assert.Equal(t, "FAILED", failed.Status)
+	assert.Equal(t, "Call limit exceeded", failed.Comment)
+}
+
+func TestStreamValueTypeMismatch(t *testing.T) {
+	_, err := decodeString[[]string](`{"status":"OK","result":{"a":1}}`)
+	assert.NotNil(t, err)
+	_, err = decodeString[Problemset](`{"status":"OK","result":[]}`)
+	assert.NotNil(t, err)
+	_, err = decodeString[[]int](`{"status":"OK","result":["a"]}`)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,174 @@
+package codeforces
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Transport performs a call to a single api method, such as "user.info".
+// Implementations only return responses with status code 200, anything else
+// is reported as an error. Transports can be stacked to add behaviour such as
+// caching or recording on top of the default one.
+type Transport interface {
+	Get(ctx context.Context, method string, params map[string]string) (*http.Response, error)
+}
+
+// adapts an ordinary function to the Transport interface
+type TransportFunc func(ctx context.Context, method string, params map[string]string) (*http.Response, error)
+
+func (f TransportFunc) Get(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+	return f(ctx, method, params)
+}
+
+// SigningTransport signs the parameters of every call with Signer before
+// passing it to Next. Without a key and a secret calls are passed on unsigned,
+// and the ones that require authentication fail with ErrAuthRequired.
+type SigningTransport struct {
+	Next Transport
+	Signer
+}
+
+// implemented by transports that can reject a call before it's sent, so that
+// the caller doesn't have to wait for the rate limiter first
+type preflighter interface {
+	preflight(method string, params map[string]string) error
+}
+
+// methods that can only be called with an api key
+var authRequiredMethods = map[string]bool{
+	"user.friends": true,
+}
+
+func requiresAuth(method string, params map[string]string) bool {
+	return authRequiredMethods[method] || params["asManager"] == "true"
+}
+
+func (t *SigningTransport) anonymous() bool {
+	return t.Key == "" && t.Secret == ""
+}
+
+func (t *SigningTransport) preflight(method string, params map[string]string) error {
+	if t.anonymous() && requiresAuth(method, params) {
+		return ErrAuthRequired
+	}
+	return nil
+}
+
+func (t *SigningTransport) Get(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+	if err := t.preflight(method, params); err != nil {
+		return nil, err
+	}
+	if t.anonymous() {
+		return t.Next.Get(ctx, method, params)
+	}
+	unsigned := url.Values{}
+	for k, v := range params {
+		unsigned.Set(k, v)
+	}
+	signed := t.Sign(method, unsigned)
+	out := make(map[string]string, len(signed))
+	for k := range signed {
+		out[k] = signed.Get(k)
+	}
+	return t.Next.Get(ctx, method, out)
+}
+
+// sends calls over http as plain GET requests
+type httpTransport struct {
+	client    *http.Client
+	endpoints *endpoints
+	// for how long a failing endpoint is skipped
+	failoverCooldown time.Duration
+	userAgent        string
+	// 0 means no limit
+	maxResponseSize int64
+}
+
+// returns a Transport that sends unsigned calls using hc, or http.DefaultClient
+// when hc is nil. Calls go to the first of baseURLs, or to codeforces.com when
+// none is given. When it can't be reached or answers with a server error, the
+// call is sent to the next one, which is then preferred for
+// DefaultFailoverCooldown. Wrap it in a SigningTransport to authenticate calls.
+func NewHTTPTransport(hc *http.Client, baseURLs ...string) Transport {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	if len(baseURLs) == 0 {
+		baseURLs = []string{defaultbaseURLString}
+	}
+	return &httpTransport{client: hc, endpoints: newEndpoints(baseURLs...), failoverCooldown: DefaultFailoverCooldown}
+}
+
+// the host that served resp can be found in resp.Request.URL.Host
+func (t *httpTransport) Get(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+	err := errNoBaseURL
+	for _, base := range t.endpoints.order() {
+		var resp *http.Response
+		resp, err = t.get(ctx, base, method, params)
+		if err == nil {
+			t.endpoints.markUp(base)
+			return resp, nil
+		}
+		if !shouldFailover(ctx, err) {
+			return nil, err
+		}
+		t.endpoints.markDown(base, t.failoverCooldown)
+	}
+	return nil, err
+}
+
+// returned by an httpTransport without any base url
+var errNoBaseURL = errors.New("no base url")
+
+func (t *httpTransport) get(ctx context.Context, baseURL, method string, params map[string]string) (*http.Response, error) {
+	base, err := url.Parse(baseURL + method)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	for k, v := range params {
+		query.Add(k, v)
+	}
+	base.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if t.userAgent != "" {
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	if id := RequestIDFromContext(ctx); id != "" {
+		req.Header.Set(RequestIDHeader, id)
+	}
+	resp, err := t.client.Do(req)
+	if err == nil && t.maxResponseSize > 0 {
+		resp.Body = &maxBytesReader{rc: resp.Body, remaining: t.maxResponseSize}
+	}
+	if err := handleResponseStatusCode(method, params, resp, err); err != nil {
+		return nil, err
+	}
+	if err := validateEnvelope(method, params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// builds a 200 response for method out of an already received body, for
+// layers answering calls without contacting the api
+func syntheticResponse(method string, body []byte) *http.Response {
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request: &http.Request{
+			Method: http.MethodGet,
+			URL:    &url.URL{Path: "/api/" + method},
+		},
+	}
+}
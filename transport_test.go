@@ -0,0 +1,119 @@
+package codeforces
+
+import (
+	"context"
+	"crypto/sha512"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func jsonResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestCustomTransport(t *testing.T) {
+	mock := TransportFunc(func(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+		assert.Equal(t, "user.info", method)
+		assert.Equal(t, map[string]string{"handles": "tourist;benq"}, params)
+		return jsonResponse(`{"status":"OK","result":[{"handle":"tourist"},{"handle":"benq"}]}`), nil
+	})
+	c := NewCustomClient(mock)
+	resp, err := c.User.Info([]string{"tourist", "benq"})
+	assert.Nil(t, err)
+	assert.Len(t, *resp, 2)
+	assert.Equal(t, "benq", (*resp)[1].Handle)
+}
+
+func TestSigningTransport(t *testing.T) {
+	var got map[string]string
+	next := TransportFunc(func(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+		got = params
+		return jsonResponse(`{"status":"OK","result":[]}`), nil
+	})
+	st := &SigningTransport{Next: next, Signer: Signer{Key: "key", Secret: "secret"}}
+	_, err := st.Get(context.Background(), "user.friends", map[string]string{"onlyOnline": "true"})
+	assert.Nil(t, err)
+	assert.Equal(t, "key", got["apiKey"])
+	assert.Equal(t, "true", got["onlyOnline"])
+	assert.NotEmpty(t, got["time"])
+	sig := got["apiSig"]
+	assert.Len(t, sig, 6+128)
+	unsigned := url.Values{}
+	for k, v := range got {
+		if k != "apiSig" {
+			unsigned.Add(k, v)
+		}
+	}
+	prefix := sig[:6]
+	hash := sha512.Sum512([]byte(prefix + "/user.friends?" + unsigned.Encode() + "#secret"))
+	assert.Equal(t, fmt.Sprintf("%x", hash), sig[6:])
+}
+
+func TestWithTransportIsRetried(t *testing.T) {
+	attempts := 0
+	mock := TransportFunc(func(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, &APIError{StatusCode: 503, Comment: "Call limit exceeded"}
+		}
+		return jsonResponse(`{"status":"OK","result":[]}`), nil
+	})
+	c := NewClient("", "", WithTransport(mock), WithRateLimiter(nil), WithRetryPolicy(fastRetryPolicy(2)))
+	resp, err := c.Contest.List(true)
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestAnonymousRequestsAreUnsigned(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.False(t, q.Has("apiKey"))
+		assert.False(t, q.Has("time"))
+		assert.False(t, q.Has("apiSig"))
+		assert.Equal(t, "true", q.Get("gym"))
+		w.WriteHeader(200)
+		_, err := w.Write([]byte(`{"status":"OK","result":[]}`))
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	cs := contestService{c}
+	resp, err := cs.List(true)
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+}
+
+func TestAnonymousAuthRequired(t *testing.T) {
+	hit := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	// the call must fail right away instead of waiting for its turn
+	l := NewRateLimiter(time.Hour)
+	assert.Nil(t, l.Wait(context.Background()))
+	WithRateLimiter(l)(c)
+	us := userService{c}
+	resp, err := us.Friends(true)
+	assert.Nil(t, resp)
+	assert.ErrorIs(t, err, ErrAuthRequired)
+
+	st := c.transport
+	_, err = st.Get(context.Background(), "contest.status", map[string]string{"contestId": "1", "asManager": "true"})
+	assert.ErrorIs(t, err, ErrAuthRequired)
+	assert.False(t, hit)
+}
@@ -78,6 +78,8 @@ type BlogEntry struct {
 	Title                   string   `json:"title"`
 	Locale                  string   `json:"locale"`
 	Tags                    []string `json:"tags"`
+	// html body of the entry, only sent by blogEntry.view
+	Content string `json:"content,omitempty"`
 }
 
 type Member struct {
@@ -124,8 +126,9 @@ type RatingChange struct {
 
 // used to parse away the status, and isolate the result
 type ResultWrapper[T any] struct {
-	Status string `json:"status"`
-	Result T      `json:"result"`
+	Status  string `json:"status"`
+	Comment string `json:"comment,omitempty"`
+	Result  T      `json:"result"`
 }
 
 type Contest struct {
@@ -145,6 +148,7 @@ type Party struct {
 	ParticipantType  string   `json:"participantType"`
 	Ghost            bool     `json:"ghost"`
 	StartTimeSeconds int      `json:"startTimeSeconds"`
+	Room             int      `json:"room,omitempty"`
 }
 
 type ProblemResult struct {
@@ -1,54 +1,252 @@
 package codeforces
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"math/rand"
 	"net/http"
+	"path"
+	"reflect"
+	"regexp"
 	"strings"
 )
 
-func unmarshalToResultWrapper[T any](rw *ResultWrapper[T], reader io.Reader) error {
-	body, err := io.ReadAll(reader)
+// turns responses with a status code other than 200 into an *APIError
+func handleResponseStatusCode(method string, params map[string]string, resp *http.Response, err error) error {
 	if err != nil {
 		return err
 	}
-	err = json.Unmarshal(body, &rw)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func handleResponseStatusCode(resp *http.Response, err error) error {
 	if resp.StatusCode != 200 {
+		defer resp.Body.Close()
 		fr := FailedRequest{}
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
 			return err
 		}
-		err = json.Unmarshal(body, &fr)
-		if err != nil {
-			return err
+		// bodies that aren't json (e.g. a proxy error page) still carry the status code
+		if json.Unmarshal(body, &fr) != nil {
+			fr.Comment = http.StatusText(resp.StatusCode)
+		}
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Method:     method,
+			Comment:    fr.Comment,
+			Params:     publicParams(params),
 		}
-		return errors.New(fmt.Sprint(resp.StatusCode) + ":" + fr.Comment)
 	}
 	return nil
 }
 
+// how much of a malformed body is kept in errors
+const snippetLength = 256
+
+// peeks at the beginning of a 200 response to make sure it's an api envelope
+// with status OK. FAILED envelopes are turned into an *APIError, anything that
+// isn't json into a *MalformedResponseError. The body is left untouched, so it
+// can still be decoded as a stream.
+func validateEnvelope(method string, params map[string]string, resp *http.Response) error {
+	br := bufio.NewReaderSize(resp.Body, snippetLength)
+	head, _ := br.Peek(snippetLength)
+	resp.Body = readCloser{br, resp.Body}
+	trimmed := bytes.TrimLeft(head, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		resp.Body.Close()
+		return &MalformedResponseError{
+			StatusCode: resp.StatusCode,
+			Method:     method,
+			Snippet:    string(bytes.TrimSpace(head)),
+		}
+	}
+	m := envelopeStatus.FindSubmatch(trimmed)
+	if m == nil || string(m[1]) == "OK" {
+		// the status is either fine or further away, decoding will take care of it
+		return nil
+	}
+	defer resp.Body.Close()
+	fr := FailedRequest{}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, &fr); err != nil {
+		return &MalformedResponseError{
+			StatusCode: resp.StatusCode,
+			Method:     method,
+			Snippet:    snippet(body),
+			Err:        err,
+		}
+	}
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     method,
+		Comment:    fr.Comment,
+		Params:     publicParams(params),
+	}
+}
+
+var envelopeStatus = regexp.MustCompile(`^\{\s*"status"\s*:\s*"(\w+)"`)
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func snippet(body []byte) string {
+	body = bytes.TrimSpace(body)
+	if len(body) > snippetLength {
+		body = body[:snippetLength]
+	}
+	return string(body)
+}
+
+// name of the api method a response belongs to, if known
+func responseMethod(resp *http.Response) string {
+	if resp.Request == nil || resp.Request.URL == nil {
+		return ""
+	}
+	return path.Base(resp.Request.URL.Path)
+}
+
+// decodes the response body as a stream, without buffering it first. The body
+// is always closed.
 func serializeResponse[T any](resp *http.Response, err error) (*T, error) {
-	err = handleResponseStatusCode(resp, err)
 	if err != nil {
 		return nil, err
 	}
-	rw := ResultWrapper[T]{}
-	err = unmarshalToResultWrapper(&rw, resp.Body)
+	var result T
+	err = decodeResponse(resp, func(dec *json.Decoder) error {
+		return streamValue(dec, reflect.ValueOf(&result).Elem(), false)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return &rw.Result, nil
+	return &result, nil
+}
+
+// like serializeResponse, but for array results: every element is passed to fn
+// as soon as it's decoded, instead of being collected. Iteration stops at the
+// first error returned by fn, which is returned as is.
+func streamResponse[T any](resp *http.Response, err error, fn func(T) error) error {
+	if err != nil {
+		return err
+	}
+	return decodeResponse(resp, func(dec *json.Decoder) error {
+		return eachElement(dec, fn)
+	})
+}
+
+// error returned by a callback, which must not be mistaken for a decoding one
+type callbackError struct {
+	err error
+}
+
+func (e *callbackError) Error() string {
+	return e.err.Error()
+}
+
+// checks the envelope of resp, using decodeResult for its result, and closes the body
+func decodeResponse(resp *http.Response, decodeResult func(dec *json.Decoder) error) error {
+	defer closeBody(resp)
+	err := decodeBody(resp, decodeResult)
+	var cbErr *callbackError
+	if errors.As(err, &cbErr) {
+		// the callback stopped decoding early, which isn't a failure of the call
+		// but leaves the rest of the body unchecked: nothing is reported, and the
+		// body is treated as one that wasn't decoded
+		return cbErr.err
+	}
+	if r, ok := resp.Body.(decodeReporter); ok {
+		r.reportDecode(err)
+	}
+	return err
+}
+
+// implemented by response bodies that need to know how decoding them went,
+// such as the ones wrapped by HookMiddleware
+type decodeReporter interface {
+	reportDecode(err error)
+}
+
+func decodeBody(resp *http.Response, decodeResult func(dec *json.Decoder) error) error {
+	head := &prefixBuffer{limit: snippetLength}
+	dec := json.NewDecoder(io.TeeReader(resp.Body, head))
+	status, comment, err := decodeEnvelope(dec, decodeResult)
+	var cbErr *callbackError
+	if errors.As(err, &cbErr) {
+		return err
+	}
+	if errors.Is(err, ErrResponseTooLarge) {
+		return err
+	}
+	if err != nil {
+		return &MalformedResponseError{
+			StatusCode: resp.StatusCode,
+			Method:     responseMethod(resp),
+			Snippet:    snippet(head.buf),
+			Err:        err,
+		}
+	}
+	if status != "OK" {
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Method:     responseMethod(resp),
+			Comment:    comment,
+		}
+	}
+	return nil
+}
+
+// drains what's left of the body (usually just a newline) before closing it,
+// so that the underlying connection can be reused
+func closeBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+}
+
+// keeps the first limit bytes written to it
+type prefixBuffer struct {
+	buf   []byte
+	limit int
+}
+
+func (b *prefixBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - len(b.buf); room > 0 {
+		if len(p) < room {
+			room = len(p)
+		}
+		b.buf = append(b.buf, p[:room]...)
+	}
+	return len(p), nil
+}
+
+// fails with ErrResponseTooLarge once more than remaining bytes are read
+type maxBytesReader struct {
+	rc        io.ReadCloser
+	remaining int64
+}
+
+func (r *maxBytesReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.rc.Read(p)
+	if int64(n) <= r.remaining {
+		r.remaining -= int64(n)
+		return n, err
+	}
+	n = int(r.remaining)
+	r.remaining = -1
+	return n, ErrResponseTooLarge
+}
+
+func (r *maxBytesReader) Close() error {
+	return r.rc.Close()
 }
 
 // non inclusive integer in range
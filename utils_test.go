@@ -0,0 +1,179 @@
+package codeforces
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type trackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestSerializeResponseClosesBody(t *testing.T) {
+	bodies := []string{
+		`{"status":"OK","result":[]}`,
+		`{"status":"FAILED","comment":"Call limit exceeded"}`,
+		`not json`,
+	}
+	for _, b := range bodies {
+		body := &trackingBody{Reader: strings.NewReader(b)}
+		_, _ = serializeResponse[[]Contest](&http.Response{StatusCode: 200, Body: body}, nil)
+		assert.True(t, body.closed, b)
+	}
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		b, err := os.ReadFile("testdata/problems/problemset/problemset.json")
+		assert.Nil(t, err)
+		_, err = w.Write(b)
+		assert.Nil(t, err)
+	}))
+	defer ts.Close()
+	c := newDefaultClientWrapper(ts.URL+"/", "", "")
+	WithMaxResponseSize(300)(c)
+	ps := problemService{c}
+	resp, err := ps.Problemset(nil)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrResponseTooLarge))
+
+	WithMaxResponseSize(1 << 20)(c)
+	resp, err = ps.Problemset(nil)
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+}
+
+func TestPrefixBuffer(t *testing.T) {
+	b := &prefixBuffer{limit: 5}
+	n, err := b.Write([]byte("abc"))
+	assert.Equal(t, 3, n)
+	assert.Nil(t, err)
+	n, err = b.Write([]byte("defgh"))
+	assert.Equal(t, 5, n)
+	assert.Nil(t, err)
+	assert.Equal(t, "abcde", string(b.buf))
+}
+
+var benchmarkFixtures = []struct {
+	name string
+	path string
+	run  func(body io.Reader, stream bool) error
+}{
+	{"problemset", "testdata/problems/problemset/problemset.json", decodeFixture[Problemset]},
+	{"status", "testdata/contest/statuswithhandle/touriststatus.json", decodeFixture[[]ContestStatus]},
+	{"standings", "testdata/contest/standings/emptyrows.json", decodeFixture[ContestStandings]},
+	{"hacks", "testdata/contest/hacks/hacks.json", decodeFixture[ContestHack]},
+	{"users", "testdata/user/info/multipleusers.json", decodeFixture[[]User]},
+}
+
+// stream selects between the current decoder and the previous approach,
+// which read the whole body before unmarshalling it
+func decodeFixture[T any](body io.Reader, stream bool) error {
+	if stream {
+		resp := &http.Response{StatusCode: 200, Body: io.NopCloser(body)}
+		_, err := serializeResponse[T](resp, nil)
+		return err
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	rw := ResultWrapper[T]{}
+	return json.Unmarshal(b, &rw)
+}
+
+// the fixtures only hold a couple of entries, so every array in their result
+// is repeated n times to get closer to the size of real responses
+func scaledFixture(b *testing.B, path string, n int) []byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	rw := ResultWrapper[json.RawMessage]{}
+	if err := json.Unmarshal(data, &rw); err != nil {
+		b.Fatal(err)
+	}
+	repeat := func(raw json.RawMessage) json.RawMessage {
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) != nil || len(items) == 0 {
+			return raw
+		}
+		scaled := make([]json.RawMessage, 0, len(items)*n)
+		for i := 0; i < n; i++ {
+			scaled = append(scaled, items...)
+		}
+		out, _ := json.Marshal(scaled)
+		return out
+	}
+	fields := map[string]json.RawMessage{}
+	if json.Unmarshal(rw.Result, &fields) == nil {
+		for k, v := range fields {
+			fields[k] = repeat(v)
+		}
+		rw.Result, _ = json.Marshal(fields)
+	} else {
+		rw.Result = repeat(rw.Result)
+	}
+	out, err := json.Marshal(rw)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return out
+}
+
+func benchmarkDecode(b *testing.B, stream bool) {
+	for _, f := range benchmarkFixtures {
+		data := scaledFixture(b, f.path, 1000)
+		b.Run(f.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if err := f.run(bytes.NewReader(data), stream); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDecodeStreaming(b *testing.B) {
+	benchmarkDecode(b, true)
+}
+
+func BenchmarkDecodeReadAll(b *testing.B) {
+	benchmarkDecode(b, false)
+}
+
+func BenchmarkProblemsetEndToEnd(b *testing.B) {
+	data, err := os.ReadFile("testdata/problems/problemset/problemset.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	mock := TransportFunc(func(ctx context.Context, method string, params map[string]string) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewReader(data))}, nil
+	})
+	ps := problemService{mock}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ps.Problemset(nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}